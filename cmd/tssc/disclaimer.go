@@ -20,7 +20,8 @@ func printDisclaimer() {
 	fmt.Fprintf(
 		os.Stderr,
 		"NOTE: The TSSC installation program generates your first deployment "+
-			"of RHADS-SSC but does not support upgrades. Each product must "+
-			"be manually reconfigured for production workloads.\n\n",
+			"of RHADS-SSC, existing deployments are upgraded with \"tssc "+
+			"upgrade\". Each product must be manually reconfigured for "+
+			"production workloads.\n\n",
 	)
 }
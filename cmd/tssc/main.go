@@ -1,10 +1,12 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/redhat-appstudio/tssc-cli/installer"
+	"github.com/redhat-appstudio/tssc-cli/pkg/subcmd"
 
 	"github.com/redhat-appstudio/helmet/api"
 	"github.com/redhat-appstudio/helmet/framework"
@@ -54,9 +56,20 @@ func main() {
 		os.Exit(1)
 	}
 
+	// TSSC-specific subcommands, and framework subcommands extensions.
+	if err := subcmd.Setup(
+		appCtx, app.ChartFS, app.Command(), appIntegrations,
+	); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to setup subcommands: %v\n", err)
+		os.Exit(1)
+	}
+
 	printDisclaimer()
 
 	if err := app.Run(); err != nil {
+		if errors.Is(err, subcmd.ErrConfigDrift) {
+			os.Exit(subcmd.ExitCodeDrift)
+		}
 		os.Exit(1)
 	}
-}
\ No newline at end of file
+}
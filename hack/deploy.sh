@@ -184,17 +184,17 @@ unshare() {
 }
 
 configure() {
-    if [ -n "${CATALOG_URL:-}" ]; then
-        export CATALOG_URL
-        yq -i '.tssc.products[] |= select(.name == "Developer Hub").properties.catalogURL=strenv(CATALOG_URL)' "${CONFIG}"
-    fi
-
     if [[ -n "${CI:-}" ]]; then
         sed -i 's/\( *ci\): .*/\1: true/' "$VALUES"
     fi
     cd "$(dirname "$CONFIG")"
     tssc_cli config --force --get --create "$(basename "$CONFIG")"
 
+    if [ -n "${CATALOG_URL:-}" ]; then
+        tssc_cli config set \
+            "'products.Developer Hub.properties.catalogURL'" "'$CATALOG_URL'"
+    fi
+
     NAMESPACE="$(
         kubectl get configmap \
         -A \
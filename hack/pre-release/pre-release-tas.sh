@@ -521,15 +521,18 @@ configure_tas() {
     CHANNEL=$(yq '.spec.channel' "$SUBSCRIPTION_FILE" 2>/dev/null || echo "stable-v1.3")
     SOURCE=$(yq '.spec.source' "$SUBSCRIPTION_FILE" 2>/dev/null || echo "rhtas-operator")
     
-    # Update config.yaml to disable subscription management for TAS
+    # Update the cluster configuration to disable subscription management for TAS
     # This prevents Helm from trying to manage the subscription that was already created via oc apply
-    config_file="$PROJECT_DIR/installer/config.yaml"
-    if [[ -f "$config_file" ]]; then
-        echo "[INFO] Updating config.yaml to disable TAS subscription management" >&2
-        yq -i '.tssc.products[] |= (select(.name == "Trusted Artifact Signer") | .properties.manageSubscription = false)' "$config_file"
-        echo "[INFO] ✓ TAS subscription management disabled in config.yaml" >&2
+    local tssc_binary="${TSSC_BINARY:-$PROJECT_DIR/bin/tssc}"
+    if kubectl get configmap -A \
+        --selector "helmet.redhat-appstudio.github.com/config=true" \
+        -o name 2>/dev/null | grep -q .; then
+        echo "[INFO] Updating the cluster configuration to disable TAS subscription management" >&2
+        "$tssc_binary" config set \
+            "products.Trusted Artifact Signer.properties.manageSubscription" false
+        echo "[INFO] ✓ TAS subscription management disabled in the cluster configuration" >&2
     else
-        echo "[WARNING] config.yaml not found at $config_file, skipping subscription management update" >&2
+        echo "[WARNING] cluster configuration not found, run 'tssc config --create' first, skipping subscription management update" >&2
     fi
     
     # Update values.yaml to use the correct source and channel from the pre-release subscription
@@ -117,8 +117,13 @@ init() {
 }
 
 action() {
-    CONFIG="$PROJECT_DIR/installer/config.yaml"
-    NAMESPACE="$(yq '.tssc.namespace' "$CONFIG")"
+    NAMESPACE="$(
+        kubectl get configmap \
+        -A \
+        --selector "helmet.redhat-appstudio.github.com/config=true" \
+        -o jsonpath="{.items[0].metadata.namespace}" 2>/dev/null || true
+    )"
+    NAMESPACE="${NAMESPACE:-tssc}"
 
     if [ -n "${CLUSTER:-}" ]; then
         echo '# Cluster'
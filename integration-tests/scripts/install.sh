@@ -78,16 +78,22 @@ echo "[INFO] auth_config=(${auth_config[*]})"
 tpl_file="installer/values.yaml.tpl"
 config_file="installer/config.yaml"
 
+# Changes a property of the cluster configuration, created by
+# "create_cluster_config".
+tssc_config_set() {
+  "${TSSC_BINARY}" config --kube-config "$KUBECONFIG" set "$1" "$2"
+}
+
 ci_enabled() {
   echo "[INFO] Turn ci to true, this is required when you perform rhtap-e2e automation test against TSSC"
-  yq -i '.tssc.settings.ci.debug = true' "${config_file}"
+  tssc_config_set settings.ci.debug true
 }
 
 update_dh_catalog_url() {
   # if DEVELOPER_HUB_CATALOG_URL is not empty string, then update the catalog url
   if [[ -n "${DEVELOPER_HUB_CATALOG_URL}" ]]; then
     echo "[INFO] Update dh catalog url with $DEVELOPER_HUB_CATALOG_URL"
-    yq -i '.tssc.products[] |= select(.name == "Developer Hub").properties.catalogURL=strenv(DEVELOPER_HUB_CATALOG_URL)' "${config_file}"
+    tssc_config_set "products.Developer Hub.properties.catalogURL" "${DEVELOPER_HUB_CATALOG_URL}"
   fi
 }
 
@@ -95,10 +101,10 @@ update_dh_auth_config() {
   # Use auth_config to determine the auth provider for Developer Hub
   if [[ " ${auth_config[*]} " =~ " gitlab " ]]; then
     echo "[INFO] Change Developer Hub auth to gitlab"
-    yq -i '.tssc.products[] |= select(.name == "Developer Hub").properties.authProvider = "gitlab"' "${config_file}"
+    tssc_config_set "products.Developer Hub.properties.authProvider" gitlab
   elif [[ " ${auth_config[*]} " =~ " github " ]]; then
     echo "[INFO] Change Developer Hub auth to github"
-    yq -i '.tssc.products[] |= select(.name == "Developer Hub").properties.authProvider = "github"' "${config_file}"
+    tssc_config_set "products.Developer Hub.properties.authProvider" github
   else
     echo "[INFO] Keep Developer Hub auth as oidc (default)"
   fi
@@ -189,16 +195,13 @@ quay_integration() {
   fi
 }
 
-# Workaround: This function has to be called before tssc import "installer/config.yaml" into cluster.
-# Currently, the tssc `config` subcommand lacks the ability to modify property values stored in cluster
 disable_acs() {
   # if "remote" is in acs_config array, then disable ACS installation
-  # Update the YAML anchor &rhacsEnabled from true to false (line 31 in config.yaml)
   if [[ " ${acs_config[*]} " =~ " remote " ]]; then
     echo "[INFO] Disable ACS installation in the TSSC configuration"
-    yq -i '.tssc.products[] |= select(.name == "Advanced Cluster Security").enabled = false' "${config_file}"
+    tssc_config_set "products.Advanced Cluster Security.enabled" false
   else
-    echo "[INFO] ACS is set to local, keeping &rhacsEnabled anchor as true"
+    echo "[INFO] ACS is set to local, keeping it enabled"
   fi
 }
 
@@ -224,14 +227,11 @@ bitbucket_integration() {
   fi
 }
 
-# Workaround: This function has to be called before tssc import "installer/config.yaml" into cluster.
-# Currently, the tssc `config` subcommand lacks the ability to modify property values stored in cluster
 disable_tpa() {
   # if "remote" is in tpa_config array, then disable TPA installation
-  # Update the enabled flag from true to false (line 7 in config.yaml)
   if [[ " ${tpa_config[*]} " =~ " remote " ]]; then
     echo "[INFO] Disable TPA installation in TSSC configuration"
-    yq -i '.tssc.products[] |= select(.name == "Trusted Profile Analyzer").enabled = false' "${config_file}"
+    tssc_config_set "products.Trusted Profile Analyzer.enabled" false
   else
     echo "[INFO] TPA is set to local, keeping enabled flag as true"
   fi
@@ -251,14 +251,11 @@ tpa_integration() {
   fi
 }
 
-# Workaround: This function has to be called before tssc import "installer/config.yaml" into cluster.
-# Currently, the tssc `config` subcommand lacks the ability to modify property values stored in cluster
 disable_tas() {
   # if "remote" is in tas_config array, then disable TAS installation
-  # Update the enabled flag from true to false
   if [[ " ${tas_config[*]} " =~ " remote " ]]; then
     echo "[INFO] Disable TAS installation in TSSC configuration"
-    yq -i '.tssc.products[] |= select(.name == "Trusted Artifact Signer").enabled = false' "${config_file}"
+    tssc_config_set "products.Trusted Artifact Signer.enabled" false
   else
     echo "[INFO] TAS is set to local, keeping enabled flag as true"
   fi
@@ -384,27 +381,21 @@ run_pre_release() {
 
 create_cluster_config() {
   echo "[INFO] Creating the installer's cluster configuration"
+  set -x
+    "${TSSC_BINARY}" config --kube-config "$KUBECONFIG" --create "$config_file" --force
+  set +x
+
+  ci_enabled
   update_dh_catalog_url
   update_dh_auth_config
   disable_acs
   disable_tpa
   disable_tas
-  
-  set -x
-  cat "$config_file"
-  set +x
 
-  echo "[INFO] Applying the cluster configuration, and showing the 'config.yaml'"
+  echo "[INFO] Cluster configuration created successfully, showing the 'config.yaml'"
   set -x
-    "${TSSC_BINARY}" config --kube-config "$KUBECONFIG" --get --create "$config_file" --force
+    "${TSSC_BINARY}" config --kube-config "$KUBECONFIG" --get
   set +x
-  
-  echo "[INFO] Cluster configuration created successfully"
-  
-  # Ensure the installer namespace exists for integrations
-  echo "[INFO] Ensuring installer namespace exists for integrations"
-  INSTALLER_NAMESPACE=$(yq '.tssc.namespace' "${config_file}" 2>/dev/null || echo "tssc")
-  kubectl get namespace "${INSTALLER_NAMESPACE}" >/dev/null 2>&1 || kubectl create namespace "${INSTALLER_NAMESPACE}"
 }
 
 configure_integrations() {
@@ -468,8 +459,7 @@ install_tssc() {
   fi
 }
 
-ci_enabled
-run_pre_release
 create_cluster_config
+run_pre_release
 configure_integrations
 install_tssc
@@ -0,0 +1,28 @@
+package annotations
+
+// RepoURI is the reverse domain notation URI used as prefix for all
+// annotations and labels managed by the installer framework.
+const RepoURI = "helmet.redhat-appstudio.github.com"
+
+// Annotation keys for Helm chart metadata.
+const (
+	ProductName          = RepoURI + "/product-name"
+	DependsOn            = RepoURI + "/depends-on"
+	SoftDependsOn        = RepoURI + "/soft-depends-on"
+	Weight               = RepoURI + "/weight"
+	UseProductNamespace  = RepoURI + "/use-product-namespace"
+	IntegrationsProvided = RepoURI + "/integrations-provided"
+	IntegrationsRequired = RepoURI + "/integrations-required"
+	EnabledIf            = RepoURI + "/enabled-if"
+	RequiresAPIs         = RepoURI + "/requires-apis"
+	ProvidesAPIs         = RepoURI + "/provides-apis"
+	RequiresCluster      = RepoURI + "/requires-cluster"
+	FailurePolicy        = RepoURI + "/failure-policy"
+	PostDeploy           = RepoURI + "/post-deploy"
+	Config               = RepoURI + "/config"
+)
+
+// Instance label on the Helm releases, and on the namespaces created by the
+// deployment, identifies the installation, its installer namespace, which owns
+// the resource.
+const Instance = RepoURI + "/instance"
@@ -0,0 +1,23 @@
+package chartfs
+
+import (
+	"io/fs"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// Interface represents the installer filesystem, which provides the Helm charts
+// payload, the configuration and the values template. The framework's embedded
+// and local overlay filesystem satisfies it.
+type Interface interface {
+	fs.FS
+
+	// ReadFile reads the named file from the local or embedded filesystem.
+	ReadFile(string) ([]byte, error)
+
+	// GetChartFiles returns the informed Helm chart path instantiated files.
+	GetChartFiles(string) (*chart.Chart, error)
+
+	// GetAllCharts retrieves all Helm charts from the filesystem.
+	GetAllCharts() ([]chart.Chart, error)
+}
@@ -0,0 +1,432 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/chartfs"
+	"github.com/redhat-appstudio/tssc-cli/pkg/constants"
+
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Settings represents a map of configuration settings.
+type Settings map[string]interface{}
+
+// ProductSpec represents a map of product name and specification.
+type Products []Product
+
+// Spec contains all configuration sections.
+type Spec struct {
+	// Settings contains the configuration for the installer settings.
+	Settings Settings `yaml:"settings"`
+	// Products contains the configuration for the installer products.
+	Products Products `yaml:"products"`
+}
+
+// Config root configuration structure.
+type Config struct {
+	cfs       chartfs.Interface // embedded filesystem
+	root      yaml.Node         // yaml data representation
+	namespace string            // installer's namespace
+	appName   string            // dynamic root key name
+
+	resourceVersion string // cluster ConfigMap resource version
+	base            string // payload as last stored in the cluster
+
+	Installer Spec `yaml:"-"` // root configuration for the installer
+}
+
+var (
+	// ErrInvalidConfig indicates the configuration content is invalid.
+	ErrInvalidConfig = errors.New("invalid configuration")
+	// ErrEmptyConfig indicates the configuration file is empty.
+	ErrEmptyConfig = errors.New("empty configuration")
+	// ErrUnmarshalConfig indicates the configuration file structure is invalid.
+	ErrUnmarshalConfig = errors.New("failed to unmarshal configuration")
+	// ErrProductNotFound indicates the addressed product is not present in the
+	// configuration.
+	ErrProductNotFound = errors.New("product not found")
+)
+
+// DefaultRelativeConfigPath default relative path to YAML configuration file.
+var DefaultRelativeConfigPath = constants.ConfigFilename
+
+// Namespace returns the installer's namespace.
+func (c *Config) Namespace() string {
+	return c.namespace
+}
+
+// GetProduct returns a product by name, or an error if the product is not found.
+func (c *Config) GetProduct(name string) (*Product, error) {
+	for i := range c.Installer.Products {
+		if c.Installer.Products[i].Name == name {
+			return &c.Installer.Products[i], nil
+		}
+	}
+	return nil, fmt.Errorf("product '%s' not found", name)
+}
+
+// GetEnabledProducts returns a map of enabled products.
+func (c *Config) GetEnabledProducts() Products {
+	enabled := Products{}
+	for _, product := range c.Installer.Products {
+		if product.Enabled {
+			enabled = append(enabled, product)
+		}
+	}
+	return enabled
+}
+
+// ApplyDefaults applies default values to the configuration.
+func (c *Config) ApplyDefaults() {
+	// Propagate the installer namespace to the products.
+	for i := range c.Installer.Products {
+		if c.Installer.Products[i].Namespace == nil {
+			ns := c.namespace
+			c.Installer.Products[i].Namespace = &ns
+		}
+	}
+}
+
+// Validate validates the configuration, checking for missing fields.
+func (c *Config) Validate() error {
+	root := c.Installer
+
+	// The installer must have a settings section.
+	if root.Settings == nil {
+		return fmt.Errorf("%w: missing settings", ErrInvalidConfig)
+	}
+
+	// Validating the products, making sure every product entry is valid.
+	for _, product := range root.Products {
+		if err := product.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecodeNode returns a struct converted from *yaml.Node.
+func (c *Config) DecodeNode() error {
+	if len(c.root.Content) == 0 {
+		return fmt.Errorf("invalid configuration: content is empty")
+	}
+	doc := c.root.Content[0]
+	if doc.Kind != yaml.MappingNode || len(doc.Content) < 2 {
+		return fmt.Errorf("invalid configuration: root must be a mapping")
+	}
+	var appNode *yaml.Node
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if doc.Content[i].Value == c.appName {
+			appNode = doc.Content[i+1]
+			break
+		}
+	}
+	if appNode == nil {
+		return fmt.Errorf("invalid configuration: missing '%s' key", c.appName)
+	}
+	// Decoding on a new specification, keys removed from the node are not kept.
+	spec := Spec{}
+	if err := appNode.Decode(&spec); err != nil {
+		return err
+	}
+	c.Installer = spec
+	return nil
+}
+
+// Set returns new configuration with updates.
+func (c *Config) Set(key string, configData any) error {
+	keyPaths, err := FlattenMap(configData, key)
+	if err != nil {
+		return err
+	}
+
+	for keyPath, value := range keyPaths {
+		keys := strings.Split(keyPath, ".")
+		if err = UpdateNestedValue(&c.root, keys, value); err != nil {
+			return err
+		}
+	}
+
+	return c.DecodeNode()
+}
+
+// SetPath sets the value on the informed dotted path, resolved by KeyPath. The
+// products addressed must be present in the configuration, ErrProductNotFound
+// is returned otherwise, see SetProduct to add new products.
+func (c *Config) SetPath(path string, value any) error {
+	keyPath, err := c.KeyPath(path)
+	if err != nil {
+		return err
+	}
+	return c.Set(keyPath, value)
+}
+
+// Unset removes the informed key path from the configuration, the path uses
+// the same notation as Set. The configuration is then re-decoded and validated.
+func (c *Config) Unset(key string) error {
+	if err := RemoveNestedValue(&c.root, strings.Split(key, ".")); err != nil {
+		return err
+	}
+	if err := c.DecodeNode(); err != nil {
+		return err
+	}
+	c.ApplyDefaults()
+	return c.Validate()
+}
+
+// PathProduct returns the product name addressed by the informed dotted path,
+// empty when the path doesn't address a product by name.
+func (c *Config) PathProduct(path string) string {
+	keys := strings.Split(strings.TrimPrefix(path, c.appName+"."), ".")
+	if len(keys) < 2 || keys[0] != "products" {
+		return ""
+	}
+	if _, err := strconv.Atoi(keys[1]); err == nil {
+		return ""
+	}
+	return keys[1]
+}
+
+// KeyPath converts a user informed dotted path into the notation employed by Set
+// and Unset. The application root key is optional, i.e. "settings.crc" becomes
+// "<appName>.settings.crc", and products are addressed either by index or name,
+// i.e. "products.<name>.enabled" becomes "<appName>.products.<index>.enabled".
+// The product name must match exactly its name or its key name.
+func (c *Config) KeyPath(path string) (string, error) {
+	keys := strings.Split(path, ".")
+	if len(keys) == 0 || keys[0] == "" {
+		return "", fmt.Errorf("%w: empty path", ErrInvalidConfig)
+	}
+	if keys[0] != c.appName {
+		keys = append([]string{c.appName}, keys...)
+	}
+	if len(keys) > 2 && keys[1] == "products" {
+		if _, err := strconv.Atoi(keys[2]); err != nil {
+			index, err := c.findProduct(keys[2])
+			if err != nil {
+				return "", err
+			}
+			keys[2] = strconv.Itoa(index)
+		}
+	}
+	return strings.Join(keys, "."), nil
+}
+
+// findProduct returns the index of the product matching exactly the informed
+// name, or key name.
+func (c *Config) findProduct(name string) (int, error) {
+	for i, p := range c.Installer.Products {
+		if p.Name == name || p.KeyName() == name {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("%w: %q", ErrProductNotFound, name)
+}
+
+// SetProduct updates a product specification in the configuration. It searches
+// for a product by its name and, if found, replaces its specification with the
+// provided `spec`, otherwise a new product entry is appended to the products.
+// The configuration is then re-decoded to reflect the changes.
+func (c *Config) SetProduct(name string, spec Product) error {
+	if len(c.root.Content) == 0 {
+		return fmt.Errorf("invalid configuration: content is empty")
+	}
+	doc := c.root.Content[0]
+
+	var appNode *yaml.Node
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if doc.Content[i].Value == c.appName {
+			appNode = doc.Content[i+1]
+			break
+		}
+	}
+	if appNode == nil {
+		return fmt.Errorf("invalid configuration: missing '%s' key", c.appName)
+	}
+
+	var productsNode *yaml.Node
+	for i := 0; i+1 < len(appNode.Content); i += 2 {
+		if appNode.Content[i].Value == "products" {
+			productsNode = appNode.Content[i+1]
+			break
+		}
+	}
+	if productsNode == nil {
+		return fmt.Errorf("invalid configuration: missing 'products' key")
+	}
+
+	if productsNode.Kind != yaml.SequenceNode {
+		return fmt.Errorf("'products' is not a sequence")
+	}
+
+	for i, productNode := range productsNode.Content {
+		// Each productNode is a MappingNode
+		var productName string
+		for j := 0; j+1 < len(productNode.Content); j += 2 {
+			if productNode.Content[j].Value == "name" {
+				productName = productNode.Content[j+1].Value
+				break
+			}
+		}
+
+		// Found it. Update the node fields in place using Set logic.
+		if productName == name {
+			// Convert the Product struct spec into a map[stringany for
+			// flattening.
+			var specMap map[string]any
+			data, err := yaml.Marshal(spec)
+			if err != nil {
+				return fmt.Errorf("failed to marshal product spec: %w", err)
+			}
+			if err := yaml.Unmarshal(data, &specMap); err != nil {
+				return fmt.Errorf("failed to unmarshal product spec: %w", err)
+			}
+
+			// Construct the path prefix for this product entry:
+			// "<appName>.products.[index]".
+			pathPrefix := fmt.Sprintf("%s.products.%d", c.appName, i)
+
+			keyPaths, err := FlattenMap(specMap, pathPrefix)
+			if err != nil {
+				return err
+			}
+
+			for keyPath, value := range keyPaths {
+				keys := strings.Split(keyPath, ".")
+				// Skip updating 'name' if it's present in the spec, as it's the
+				// lookup key.
+				if keys[len(keys)-1] == "name" {
+					continue
+				}
+
+				if err = UpdateNestedValue(&c.root, keys, value); err != nil {
+					return err
+				}
+			}
+
+			return c.DecodeNode()
+		}
+	}
+
+	// The product is not found, appending a new product entry to the sequence.
+	spec.Name = name
+	productNode, err := valueNode(name, spec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal product spec: %w", err)
+	}
+	productsNode.Content = append(productsNode.Content, productNode)
+	return c.DecodeNode()
+}
+
+// MarshalYAML marshals the Config into a YAML byte array.
+func (c *Config) MarshalYAML() ([]byte, error) {
+	var buf bytes.Buffer
+	if len(c.root.Content) == 0 {
+		return nil, fmt.Errorf("invalid configuration format: content is nil or empty")
+	}
+	buf.WriteString("---\n")
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	defer encoder.Close()
+	if err := encoder.Encode(c.root.Content[0]); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalYAML Un-marshals the YAML payload into the Config struct, checking the
+// validity of the configuration.
+func (c *Config) UnmarshalYAML(payload []byte) error {
+	if len(payload) == 0 {
+		return ErrEmptyConfig
+	}
+	if err := yaml.Unmarshal(payload, &c.root); err != nil {
+		return fmt.Errorf("%w: %w", ErrUnmarshalConfig, err)
+	}
+	if err := c.DecodeNode(); err != nil {
+		return fmt.Errorf("%w: %w", ErrUnmarshalConfig, err)
+	}
+	c.ApplyDefaults()
+	return c.Validate()
+}
+
+// String returns this configuration as string, indented with two spaces.
+func (c *Config) String() string {
+	data, err := c.MarshalYAML()
+	if err != nil {
+		panic(err)
+	}
+	return string(data)
+}
+
+// Clone returns a deep copy of the configuration, reloaded from its payload, the
+// cluster tracking information is copied as well.
+func (c *Config) Clone() (*Config, error) {
+	payload, err := c.MarshalYAML()
+	if err != nil {
+		return nil, err
+	}
+	cl, err := NewConfigFromBytes(payload, c.namespace, c.appName)
+	if err != nil {
+		return nil, err
+	}
+	cl.cfs = c.cfs
+	cl.resourceVersion = c.resourceVersion
+	cl.base = c.base
+	return cl, nil
+}
+
+// track records the ConfigMap resource version and payload the configuration
+// corresponds to, used as preconditions and merge base for updates.
+func (c *Config) track(cm *corev1.ConfigMap) {
+	c.resourceVersion = cm.GetResourceVersion()
+	c.base = cm.Data[constants.ConfigFilename]
+}
+
+// NewConfigFromFile returns a new Config instance based on the informed file.
+func NewConfigFromFile(
+	cfs chartfs.Interface,
+	configPath string,
+	namespace string,
+	appName string,
+) (*Config, error) {
+	c := &Config{cfs: cfs, namespace: namespace, appName: appName}
+	var err error
+	payload, err := c.cfs.ReadFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.UnmarshalYAML(payload); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewConfigFromBytes instantiates a new Config from the bytes payload informed.
+func NewConfigFromBytes(
+	payload []byte,
+	namespace string,
+	appName string,
+) (*Config, error) {
+	c := &Config{namespace: namespace, appName: appName}
+	if err := c.UnmarshalYAML(payload); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewConfigDefault returns a new Config instance with default values, i.e. the
+// configuration payload is loading embedded data.
+func NewConfigDefault(
+	cfs chartfs.Interface,
+	namespace string,
+	appName string,
+) (*Config, error) {
+	return NewConfigFromFile(cfs, DefaultRelativeConfigPath, namespace, appName)
+}
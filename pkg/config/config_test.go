@@ -0,0 +1,145 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+const testConfig = `---
+tssc:
+  settings:
+    crc: false
+  products:
+    - name: Developer Hub
+      enabled: true
+      properties:
+        authProvider: github
+`
+
+func newTestConfig(t *testing.T) *Config {
+	t.Helper()
+	cfg, err := NewConfigFromBytes([]byte(testConfig), "tssc", "tssc")
+	if err != nil {
+		t.Fatalf("loading configuration: %v", err)
+	}
+	return cfg
+}
+
+func TestConfigSetPath(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string
+		value any
+		err   error
+		check func(*Config) bool
+	}{{
+		name:  "setting",
+		path:  "settings.crc",
+		value: true,
+		check: func(c *Config) bool { return c.Installer.Settings["crc"] == true },
+	}, {
+		name:  "setting with different case",
+		path:  "settings.CRC",
+		value: true,
+		check: func(c *Config) bool {
+			return c.Installer.Settings["crc"] == false &&
+				c.Installer.Settings["CRC"] == true
+		},
+	}, {
+		name:  "intermediate key with different case",
+		path:  "Settings.crc",
+		value: true,
+		check: func(c *Config) bool { return c.Installer.Settings["crc"] == false },
+	}, {
+		name:  "product by exact name",
+		path:  "products.Developer Hub.enabled",
+		value: false,
+		check: func(c *Config) bool { return !c.Installer.Products[0].Enabled },
+	}, {
+		name:  "product by key name",
+		path:  "tssc.products.Developer_Hub.properties.authProvider",
+		value: "gitlab",
+		check: func(c *Config) bool {
+			return c.Installer.Products[0].Properties["authProvider"] == "gitlab"
+		},
+	}, {
+		name:  "product by index",
+		path:  "products.0.enabled",
+		value: false,
+		check: func(c *Config) bool { return !c.Installer.Products[0].Enabled },
+	}, {
+		name:  "product name with different case",
+		path:  "products.developer hub.enabled",
+		value: false,
+		err:   ErrProductNotFound,
+	}, {
+		name:  "unknown product",
+		path:  "products.Developr Hub.enabled",
+		value: true,
+		err:   ErrProductNotFound,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(t)
+			err := cfg.SetPath(tt.path, tt.value)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected error %v, got %v", tt.err, err)
+				}
+				if len(cfg.Installer.Products) != 1 {
+					t.Fatalf("expected no product added, got %d products",
+						len(cfg.Installer.Products))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.check(cfg) {
+				t.Fatalf("unexpected configuration:\n%s", cfg.String())
+			}
+		})
+	}
+}
+
+func TestConfigPathProduct(t *testing.T) {
+	cfg := newTestConfig(t)
+	for path, expected := range map[string]string{
+		"products.Developer Hub.enabled":      "Developer Hub",
+		"tssc.products.My Product":            "My Product",
+		"products.1.enabled":                  "",
+		"settings.crc":                        "",
+		"products":                            "",
+		"tssc.products.Developer_Hub.enabled": "Developer_Hub",
+	} {
+		if name := cfg.PathProduct(path); name != expected {
+			t.Errorf("path %q: expected %q, got %q", path, expected, name)
+		}
+	}
+}
+
+func TestConfigUnset(t *testing.T) {
+	for _, path := range []string{
+		"settings.crc",
+		"products.Developer Hub.properties.authProvider",
+	} {
+		t.Run(path, func(t *testing.T) {
+			cfg := newTestConfig(t)
+			keyPath, err := cfg.KeyPath(path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err = cfg.Unset(keyPath); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, ok := cfg.Installer.Settings["crc"]; ok && path == "settings.crc" {
+				t.Errorf("setting still decoded: %v", cfg.Installer.Settings)
+			}
+			props := cfg.Installer.Products[0].Properties
+			if _, ok := props["authProvider"]; ok && path != "settings.crc" {
+				t.Errorf("property still decoded: %v", props)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ChangeKind describes the kind of a configuration change.
+type ChangeKind string
+
+const (
+	// ProductAdded the product is only present on the target configuration.
+	ProductAdded ChangeKind = "product-added"
+	// ProductRemoved the product is only present on the source configuration.
+	ProductRemoved ChangeKind = "product-removed"
+	// ProductEnabled the product is enabled on the target configuration.
+	ProductEnabled ChangeKind = "product-enabled"
+	// ProductDisabled the product is disabled on the target configuration.
+	ProductDisabled ChangeKind = "product-disabled"
+	// NamespaceChanged the product namespace differs.
+	NamespaceChanged ChangeKind = "namespace-changed"
+	// ValueAdded the setting or property is only present on the target.
+	ValueAdded ChangeKind = "value-added"
+	// ValueRemoved the setting or property is only present on the source.
+	ValueRemoved ChangeKind = "value-removed"
+	// ValueChanged the setting or property value differs.
+	ValueChanged ChangeKind = "value-changed"
+)
+
+// Change represents a single semantic difference between two configurations.
+type Change struct {
+	Kind    ChangeKind `json:"kind"`              // kind of change
+	Product string     `json:"product,omitempty"` // product name, when any
+	Path    string     `json:"path"`              // dotted configuration path
+	From    any        `json:"from,omitempty"`    // source value
+	To      any        `json:"to,omitempty"`      // target value
+}
+
+// String returns the change in a human readable form.
+func (c Change) String() string {
+	switch c.Kind {
+	case ProductAdded:
+		return fmt.Sprintf("+ product %q added", c.Product)
+	case ProductRemoved:
+		return fmt.Sprintf("- product %q removed", c.Product)
+	case ProductEnabled:
+		return fmt.Sprintf("~ product %q enabled", c.Product)
+	case ProductDisabled:
+		return fmt.Sprintf("~ product %q disabled", c.Product)
+	case ValueAdded:
+		return fmt.Sprintf("+ %s: %v", c.Path, c.To)
+	case ValueRemoved:
+		return fmt.Sprintf("- %s: %v", c.Path, c.From)
+	default:
+		return fmt.Sprintf("~ %s: %v -> %v", c.Path, c.From, c.To)
+	}
+}
+
+// Diff represents the semantic differences between a source and a target
+// configuration, i.e. the changes needed to turn the source into the target.
+type Diff struct {
+	Changes []Change `json:"changes"` // ordered changes
+}
+
+// HasDrift checks whether the configurations differ.
+func (d *Diff) HasDrift() bool {
+	return len(d.Changes) > 0
+}
+
+// Summary returns a single line summary of the changes, listing the first few
+// changes and counting the remaining.
+func (d *Diff) Summary() string {
+	const limit = 3
+	if !d.HasDrift() {
+		return "no changes"
+	}
+	items := []string{}
+	for i, c := range d.Changes {
+		if i == limit {
+			items = append(items,
+				fmt.Sprintf("and %d more", len(d.Changes)-limit))
+			break
+		}
+		items = append(items, c.String())
+	}
+	return strings.Join(items, "; ")
+}
+
+// PrintText prints the changes to the writer, one per line.
+func (d *Diff) PrintText(w io.Writer) {
+	for _, c := range d.Changes {
+		fmt.Fprintf(w, "  %s\n", c)
+	}
+}
+
+// PrintJSON prints the drift state and the changes to the writer as JSON.
+func (d *Diff) PrintJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Drift   bool     `json:"drift"`
+		Changes []Change `json:"changes"`
+	}{
+		Drift:   d.HasDrift(),
+		Changes: d.Changes,
+	})
+}
+
+// compareValues compares the flattened source and target maps, recording the
+// changes with the keys prefixed by the informed path.
+func (d *Diff) compareValues(product, path string, source, target map[string]any) {
+	from, _ := FlattenMap(source, path)
+	to, _ := FlattenMap(target, path)
+
+	keys := make([]string, 0, len(from)+len(to))
+	for k := range from {
+		keys = append(keys, k)
+	}
+	for k := range to {
+		if _, ok := from[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		f, inFrom := from[k]
+		t, inTo := to[k]
+		c := Change{Product: product, Path: k, From: f, To: t}
+		switch {
+		case !inFrom:
+			c.Kind = ValueAdded
+		case !inTo:
+			c.Kind = ValueRemoved
+		case !reflect.DeepEqual(f, t):
+			c.Kind = ValueChanged
+		default:
+			continue
+		}
+		d.Changes = append(d.Changes, c)
+	}
+}
+
+// NewDiff compares the source and target configurations semantically: settings,
+// products added or removed, enabled or disabled, namespace and properties
+// changes. Products are matched by name.
+func NewDiff(source, target *Config) *Diff {
+	d := &Diff{Changes: []Change{}}
+	d.compareValues("", "settings",
+		map[string]any(source.Installer.Settings),
+		map[string]any(target.Installer.Settings))
+
+	for _, s := range source.Installer.Products {
+		if _, err := target.GetProduct(s.Name); err != nil {
+			d.Changes = append(d.Changes, Change{
+				Kind:    ProductRemoved,
+				Product: s.Name,
+				Path:    "products." + s.Name,
+			})
+		}
+	}
+	for _, t := range target.Installer.Products {
+		path := "products." + t.Name
+		s, err := source.GetProduct(t.Name)
+		if err != nil {
+			d.Changes = append(d.Changes, Change{
+				Kind:    ProductAdded,
+				Product: t.Name,
+				Path:    path,
+			})
+			continue
+		}
+		if s.Enabled != t.Enabled {
+			c := Change{
+				Kind:    ProductEnabled,
+				Product: t.Name,
+				Path:    path + ".enabled",
+				From:    s.Enabled,
+				To:      t.Enabled,
+			}
+			if !t.Enabled {
+				c.Kind = ProductDisabled
+			}
+			d.Changes = append(d.Changes, c)
+		}
+		if s.GetNamespace() != t.GetNamespace() {
+			d.Changes = append(d.Changes, Change{
+				Kind:    NamespaceChanged,
+				Product: t.Name,
+				Path:    path + ".namespace",
+				From:    s.GetNamespace(),
+				To:      t.GetNamespace(),
+			})
+		}
+		d.compareValues(t.Name, path+".properties", s.Properties, t.Properties)
+	}
+	return d
+}
@@ -0,0 +1,264 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/annotations"
+	"github.com/redhat-appstudio/tssc-cli/pkg/k8s"
+
+	"gopkg.in/yaml.v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// HistoryFilename the ConfigMap key holding the configuration revisions.
+	HistoryFilename = "history.yaml"
+	// HistoryLabel identifies the ConfigMap holding the configuration revisions.
+	HistoryLabel = annotations.RepoURI + "/config-history"
+	// DefaultHistoryLimit the amount of configuration revisions kept.
+	DefaultHistoryLimit = 10
+)
+
+// Sources of configuration changes, recorded as the revision author.
+const (
+	// AuthorCLI changes made by the command line interface.
+	AuthorCLI = "cli"
+	// AuthorJob changes made by the command line running inside the cluster.
+	AuthorJob = "job"
+	// AuthorMCP changes made by the MCP server tools.
+	AuthorMCP = "mcp"
+	// AuthorUnknown changes made outside of the installer, or before the history
+	// has been recorded.
+	AuthorUnknown = "unknown"
+)
+
+// ErrRevisionNotFound the informed revision is not in the history.
+var ErrRevisionNotFound = errors.New("configuration revision not found")
+
+// Revision represents a configuration stored in the cluster at some point.
+type Revision struct {
+	Revision  int       `yaml:"revision"`  // sequential revision number
+	Author    string    `yaml:"author"`    // source of the change
+	Timestamp time.Time `yaml:"timestamp"` // when the change was recorded
+	Summary   string    `yaml:"summary"`   // change summary
+	Config    string    `yaml:"config"`    // configuration payload
+}
+
+// History represents the last configuration revisions, oldest first.
+type History struct {
+	Revisions []Revision `yaml:"revisions"`
+}
+
+// Latest returns the latest revision, or nil when the history is empty.
+func (h *History) Latest() *Revision {
+	if len(h.Revisions) == 0 {
+		return nil
+	}
+	return &h.Revisions[len(h.Revisions)-1]
+}
+
+// Get returns the informed revision.
+func (h *History) Get(revision int) (*Revision, error) {
+	for i := range h.Revisions {
+		if h.Revisions[i].Revision == revision {
+			return &h.Revisions[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %d", ErrRevisionNotFound, revision)
+}
+
+// add appends a new revision, keeping only the last revisions up to the limit.
+func (h *History) add(author, summary, payload string, limit int) *Revision {
+	number := 1
+	if latest := h.Latest(); latest != nil {
+		number = latest.Revision + 1
+	}
+	h.Revisions = append(h.Revisions, Revision{
+		Revision:  number,
+		Author:    author,
+		Timestamp: time.Now().UTC(),
+		Summary:   summary,
+		Config:    payload,
+	})
+	if len(h.Revisions) > limit {
+		h.Revisions = h.Revisions[len(h.Revisions)-limit:]
+	}
+	return h.Latest()
+}
+
+// Print prints the revisions to the writer as a table, newest first. The
+// current revision is marked with an asterisk.
+func (h *History) Print(w io.Writer) {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(table, "Revision\tTimestamp\tAuthor\tSummary\n")
+	for i := len(h.Revisions) - 1; i >= 0; i-- {
+		r := h.Revisions[i]
+		current := ""
+		if i == len(h.Revisions)-1 {
+			current = "*"
+		}
+		fmt.Fprintf(table, "%d%s\t%s\t%s\t%s\n",
+			r.Revision,
+			current,
+			r.Timestamp.Format(time.RFC3339),
+			r.Author,
+			r.Summary,
+		)
+	}
+	table.Flush()
+}
+
+// HistoryManager the actor responsible for the configuration revisions, stored
+// in a ConfigMap next to the installer configuration.
+type HistoryManager struct {
+	kube  k8s.Interface // kubernetes client
+	name  string        // configmap name
+	limit int           // amount of revisions kept
+}
+
+// Name returns the ConfigMap name.
+func (m *HistoryManager) Name() string {
+	return m.name
+}
+
+// parse parses the configuration revisions payload, empty when missing.
+func (m *HistoryManager) parse(namespace, payload string) (*History, error) {
+	h := &History{}
+	if err := yaml.Unmarshal([]byte(payload), h); err != nil {
+		return nil, fmt.Errorf("parsing %s/%s: %w", namespace, m.name, err)
+	}
+	return h, nil
+}
+
+// Get retrieves the configuration revisions from the informed namespace, an
+// empty history is returned when nothing has been recorded yet.
+func (m *HistoryManager) Get(
+	ctx context.Context,
+	namespace string,
+) (*History, error) {
+	coreClient, err := m.kube.CoreV1ClientSet(namespace)
+	if err != nil {
+		return nil, err
+	}
+	cm, err := coreClient.ConfigMaps(namespace).
+		Get(ctx, m.name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return &History{}, nil
+		}
+		return nil, err
+	}
+	return m.parse(namespace, cm.Data[HistoryFilename])
+}
+
+// update applies the mutation on the latest configuration revisions in the
+// informed namespace, creating the ConfigMap when it doesn't exist yet. On
+// conflict the mutation is applied again on the latest revisions. The mutation
+// returns whether the revisions changed, nothing is stored otherwise.
+func (m *HistoryManager) update(
+	ctx context.Context,
+	namespace string,
+	mutate func(*History) (bool, error),
+) error {
+	return k8s.UpdateConfigMap(
+		ctx,
+		m.kube,
+		namespace,
+		m.name,
+		map[string]string{HistoryLabel: "true"},
+		func(data map[string]string) (bool, error) {
+			h, err := m.parse(namespace, data[HistoryFilename])
+			if err != nil {
+				return false, err
+			}
+			changed, err := mutate(h)
+			if err != nil || !changed {
+				return false, err
+			}
+			payload, err := yaml.Marshal(h)
+			if err != nil {
+				return false, err
+			}
+			data[HistoryFilename] = string(payload)
+			return true, nil
+		},
+	)
+}
+
+// Delete removes the configuration revisions from the informed namespace, a
+// missing history is not an error.
+func (m *HistoryManager) Delete(ctx context.Context, namespace string) error {
+	coreClient, err := m.kube.CoreV1ClientSet(namespace)
+	if err != nil {
+		return err
+	}
+	err = coreClient.ConfigMaps(namespace).
+		Delete(ctx, m.name, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// Record records the current configuration as a new revision, by the informed
+// author. The summary describes the changes from the previous configuration,
+// prefixed by the informed note, when any. The previous configuration is nil
+// when it has just been created.
+//
+// When the previous configuration isn't the latest revision, it's recorded
+// first by an unknown author, so changes made outside of the installer are not
+// lost. Nothing is recorded when the configuration is unchanged.
+func (m *HistoryManager) Record(
+	ctx context.Context,
+	author string,
+	note string,
+	previous *Config,
+	current *Config,
+) (*Revision, error) {
+	payload := current.String()
+	if previous != nil && previous.String() == payload {
+		return nil, nil
+	}
+	summary := "configuration created"
+	if previous != nil {
+		summary = NewDiff(previous, current).Summary()
+	}
+	if note != "" {
+		summary = fmt.Sprintf("%s: %s", note, summary)
+	}
+
+	var revision *Revision
+	err := m.update(ctx, current.Namespace(), func(h *History) (bool, error) {
+		revision = nil
+		latest := h.Latest()
+		if latest != nil && latest.Config == payload {
+			return false, nil
+		}
+		if previous != nil &&
+			(latest == nil || latest.Config != previous.String()) {
+			h.add(AuthorUnknown, "untracked change", previous.String(), m.limit)
+		}
+		revision = h.add(author, summary, payload, m.limit)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// NewHistoryManager instantiates the HistoryManager, the ConfigMap is named
+// after the application as "{appName}-config-history".
+func NewHistoryManager(kube k8s.Interface, appName string) *HistoryManager {
+	return &HistoryManager{
+		kube:  kube,
+		name:  fmt.Sprintf("%s-config-history", appName),
+		limit: DefaultHistoryLimit,
+	}
+}
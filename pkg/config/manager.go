@@ -0,0 +1,299 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/annotations"
+	"github.com/redhat-appstudio/tssc-cli/pkg/constants"
+	"github.com/redhat-appstudio/tssc-cli/pkg/flags"
+	"github.com/redhat-appstudio/tssc-cli/pkg/k8s"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+// ConfigMapManager the actor responsible for managing installer configuration in
+// the cluster.
+//
+//nolint:revive
+type ConfigMapManager struct {
+	kube     k8s.Interface // kubernetes client
+	name     string        // configmap name
+	appName  string        // config root key
+	instance string        // installation namespace, empty for any
+	schema   *Schema       // validates the written configuration, optional
+}
+
+// Selector label selector for installer configuration.
+const Selector = annotations.Config + "=true"
+
+// SetSchema validates every configuration created or updated by the manager
+// against the informed schema, before it's written to the cluster.
+func (m *ConfigMapManager) SetSchema(schema *Schema) {
+	m.schema = schema
+}
+
+// Validate validates the configuration as it would be written to the cluster,
+// when the schema is set.
+func (m *ConfigMapManager) Validate(cfg *Config) error {
+	if m.schema == nil {
+		return nil
+	}
+	if err := m.schema.ValidatePayload(cfg); err != nil {
+		return fmt.Errorf("ConfigMap %s/%s: %w", cfg.Namespace(), m.name, err)
+	}
+	return nil
+}
+
+// Name returns the ConfigMap name.
+func (m *ConfigMapManager) Name() string {
+	return m.name
+}
+
+var (
+	// ErrConfigMapNotFound when the configmap isn't created in the cluster.
+	ErrConfigMapNotFound = errors.New("cluster configmap not found")
+	// ErrMultipleConfigMapFound when the label selector find multiple resources.
+	ErrMultipleConfigMapFound = errors.New("multiple cluster configmaps found")
+	// ErrIncompleteConfigMap when the ConfigMap exists, but doesn't contain the
+	// expected payload.
+	ErrIncompleteConfigMap = errors.New("invalid configmap found in the cluster")
+)
+
+// GetConfigMap retrieves the ConfigMap from the cluster, checking if a single
+// resource is present. When the instance is informed, only its namespace is
+// inspected, otherwise all namespaces.
+func (m *ConfigMapManager) GetConfigMap(
+	ctx context.Context,
+) (*corev1.ConfigMap, error) {
+	coreClient, err := m.kube.CoreV1ClientSet(m.instance)
+	if err != nil {
+		return nil, err
+	}
+
+	// Listing all ConfigMaps matching the label selector.
+	configMapList, err := coreClient.ConfigMaps(m.instance).List(
+		ctx,
+		metav1.ListOptions{LabelSelector: Selector},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// When no ConfigMaps matching criteria is found in the cluster.
+	if len(configMapList.Items) == 0 {
+		if m.instance != "" {
+			return nil, fmt.Errorf(
+				"%w: using label selector %q on namespace %q",
+				ErrConfigMapNotFound,
+				Selector,
+				m.instance,
+			)
+		}
+		return nil, fmt.Errorf(
+			"%w: using label selector %q",
+			ErrConfigMapNotFound,
+			Selector,
+		)
+	}
+	// Also, important to error out when multiple ConfigMaps are present in the
+	// cluster. Collecting and printing out the resources found by the label
+	// selector.
+	if len(configMapList.Items) > 1 {
+		configMaps := []string{}
+		for _, cm := range configMapList.Items {
+			configMaps = append(
+				configMaps,
+				fmt.Sprintf("%s/%s", cm.GetNamespace(), cm.GetName()),
+			)
+		}
+		return nil, fmt.Errorf(
+			"%w: multiple configmaps found on namespace/name pairs: %v, "+
+				"select the installation namespace with --%s",
+			ErrMultipleConfigMapFound,
+			configMaps,
+			flags.InstanceFlag,
+		)
+	}
+	return &configMapList.Items[0], nil
+}
+
+// GetConfig retrieves configuration from a cluster's ConfigMap.
+func (m *ConfigMapManager) GetConfig(ctx context.Context) (*Config, error) {
+	configMap, err := m.GetConfigMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	payload, ok := configMap.Data[constants.ConfigFilename]
+	if !ok || len(payload) == 0 {
+		return nil, fmt.Errorf(
+			"%w: key %q not found in ConfigMap %s/%s",
+			ErrIncompleteConfigMap,
+			constants.ConfigFilename,
+			configMap.GetNamespace(),
+			configMap.GetName(),
+		)
+	}
+
+	cfg, err := NewConfigFromBytes(
+		[]byte(payload),
+		configMap.GetNamespace(),
+		m.appName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	cfg.track(configMap)
+	return cfg, nil
+}
+
+// configMapForConfig generate a ConfigMap resource based on informed Config.
+func (m *ConfigMapManager) configMapForConfig(cfg *Config) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      m.name,
+			Namespace: cfg.Namespace(),
+			Labels: map[string]string{
+				annotations.Config: "true",
+			},
+		},
+		Data: map[string]string{
+			constants.ConfigFilename: cfg.String(),
+		},
+	}
+}
+
+// Create Bootstrap a ConfigMap with the provided configuration, validated against
+// the schema when set.
+func (m *ConfigMapManager) Create(ctx context.Context, cfg *Config) error {
+	if err := m.Validate(cfg); err != nil {
+		return err
+	}
+	cm := m.configMapForConfig(cfg)
+	coreClient, err := m.kube.CoreV1ClientSet(cfg.Namespace())
+	if err != nil {
+		return err
+	}
+	created, err := coreClient.
+		ConfigMaps(cfg.Namespace()).
+		Create(ctx, cm, metav1.CreateOptions{})
+	if err != nil {
+		return err
+	}
+	cfg.track(created)
+	return nil
+}
+
+// rebase merges the configuration changes on top of the latest configuration in
+// the cluster, the informed configuration is replaced by the merge result.
+func (m *ConfigMapManager) rebase(ctx context.Context, cfg *Config) error {
+	latest, err := m.GetConfig(ctx)
+	if err != nil {
+		return err
+	}
+	base, err := NewConfigFromBytes([]byte(cfg.base), cfg.namespace, cfg.appName)
+	if err != nil {
+		return err
+	}
+	merged, err := Merge(base, cfg, latest)
+	if err != nil {
+		return err
+	}
+	cfg.root = merged.root
+	cfg.Installer = merged.Installer
+	cfg.resourceVersion = merged.resourceVersion
+	cfg.base = merged.base
+	return nil
+}
+
+// Update updates a ConfigMap with informed configuration. When the configuration
+// has been loaded from the cluster, the update is conditioned to the resource
+// version loaded, so concurrent changes are not overwritten. On conflict, the
+// changes are merged on top of the latest configuration and the update is
+// retried, unless the same fields were changed concurrently, then
+// ErrConfigConflict is returned. Configurations not loaded from the cluster
+// overwrite it. The configuration, merged or not, is validated against the
+// schema when set.
+func (m *ConfigMapManager) Update(ctx context.Context, cfg *Config) error {
+	coreClient, err := m.kube.CoreV1ClientSet(cfg.Namespace())
+	if err != nil {
+		return err
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := m.Validate(cfg); err != nil {
+			return err
+		}
+		cm := m.configMapForConfig(cfg)
+		cm.SetResourceVersion(cfg.resourceVersion)
+		updated, err := coreClient.
+			ConfigMaps(cfg.Namespace()).
+			Update(ctx, cm, metav1.UpdateOptions{})
+		if err == nil {
+			cfg.track(updated)
+			return nil
+		}
+		if !apierrors.IsConflict(err) || cfg.resourceVersion == "" {
+			return err
+		}
+		if rebaseErr := m.rebase(ctx, cfg); rebaseErr != nil {
+			return rebaseErr
+		}
+		// Returning the conflict error to retry the update.
+		return err
+	})
+}
+
+// Restore replaces the informed ConfigMap revision with the configuration, the
+// update is conditioned to the ConfigMap resource version, thus concurrent
+// changes made after that revision are merged on top instead of overwritten.
+func (m *ConfigMapManager) Restore(
+	ctx context.Context,
+	cm *corev1.ConfigMap,
+	cfg *Config,
+) error {
+	restored, err := cfg.Clone()
+	if err != nil {
+		return err
+	}
+	restored.track(cm)
+	return m.Update(ctx, restored)
+}
+
+// Delete find and delete the ConfigMap from the cluster.
+func (m *ConfigMapManager) Delete(ctx context.Context) error {
+	cm, err := m.GetConfigMap(ctx)
+	if err != nil {
+		return err
+	}
+
+	coreClient, err := m.kube.CoreV1ClientSet(cm.GetNamespace())
+	if err != nil {
+		return err
+	}
+
+	return coreClient.ConfigMaps(cm.GetNamespace()).
+		Delete(ctx, cm.GetName(), metav1.DeleteOptions{})
+}
+
+// NewConfigMapManager instantiates the ConfigMapManager.
+// The appName parameter is used to generate the ConfigMap name as "{appName}-config"
+// and, with hyphens replaced by underscores, as the YAML root key for config
+// decoding. The instance is the installation namespace, when empty the
+// configuration is looked up on all namespaces.
+func NewConfigMapManager(
+	kube k8s.Interface,
+	appName string,
+	instance string,
+) *ConfigMapManager {
+	return &ConfigMapManager{
+		kube:     kube,
+		name:     fmt.Sprintf("%s-config", appName),
+		appName:  strings.ReplaceAll(appName, "-", "_"),
+		instance: instance,
+	}
+}
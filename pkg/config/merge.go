@@ -0,0 +1,127 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ErrConfigConflict the same configuration field has been changed concurrently
+// with different values.
+var ErrConfigConflict = errors.New("conflicting configuration change")
+
+// overlaps checks whether the dotted paths address the same field, or one is
+// nested on the other.
+func overlaps(a, b string) bool {
+	return a == b ||
+		strings.HasPrefix(a, b+".") ||
+		strings.HasPrefix(b, a+".")
+}
+
+// sameChange checks whether both changes have the same outcome, the change a
+// is taken from the configuration ours, and b from theirs. Added products carry
+// no value, thus their specs are compared.
+func sameChange(ours, theirs *Config, a, b Change) bool {
+	if a.Path != b.Path || a.Kind != b.Kind {
+		return false
+	}
+	if a.Kind != ProductAdded {
+		return reflect.DeepEqual(a.To, b.To)
+	}
+	ourSpec, err := ours.GetProduct(a.Product)
+	if err != nil {
+		return false
+	}
+	theirSpec, err := theirs.GetProduct(b.Product)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(ourSpec, theirSpec)
+}
+
+// applyChange applies the change, taken from the source configuration, on the
+// target configuration.
+func applyChange(target, source *Config, c Change) error {
+	switch c.Kind {
+	case ProductAdded:
+		spec, err := source.GetProduct(c.Product)
+		if err != nil {
+			return err
+		}
+		return target.SetProduct(c.Product, *spec)
+	case ProductRemoved, ValueRemoved:
+		keyPath, err := target.KeyPath(c.Path)
+		if err != nil {
+			return err
+		}
+		return target.Unset(keyPath)
+	default:
+		return target.SetPath(c.Path, c.To)
+	}
+}
+
+// Merge performs a three-way merge of the configurations: the changes from base
+// to ours are applied on top of theirs, the latest configuration. When the same
+// field is changed on both sides with different values, ErrConfigConflict is
+// returned listing the conflicting paths. The informed configurations are not
+// modified.
+func Merge(base, ours, theirs *Config) (*Config, error) {
+	merged, err := theirs.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	// The configurations are compared on clones, so the informed ones are kept
+	// unchanged. Changes made in place may have reset the defaults, which would
+	// be seen as differences otherwise.
+	clones := make([]*Config, 0, 3)
+	for _, c := range []*Config{base, ours, theirs} {
+		cl, err := c.Clone()
+		if err != nil {
+			return nil, err
+		}
+		cl.ApplyDefaults()
+		clones = append(clones, cl)
+	}
+	base, ours, theirs = clones[0], clones[1], clones[2]
+
+	ourChanges := NewDiff(base, ours).Changes
+	theirChanges := NewDiff(base, theirs).Changes
+
+	conflicts := []string{}
+	pending := []Change{}
+	for _, o := range ourChanges {
+		applied := false
+		for _, t := range theirChanges {
+			if sameChange(ours, theirs, o, t) {
+				applied = true
+				continue
+			}
+			if overlaps(o.Path, t.Path) && !slices.Contains(conflicts, o.Path) {
+				conflicts = append(conflicts, o.Path)
+			}
+		}
+		if !applied {
+			pending = append(pending, o)
+		}
+	}
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf(
+			"%w: %s changed on the cluster concurrently, reload the "+
+				"configuration and try again",
+			ErrConfigConflict, strings.Join(conflicts, ", "))
+	}
+
+	for _, c := range pending {
+		if err = applyChange(merged, ours, c); err != nil {
+			return nil, fmt.Errorf("merging %q: %w", c.Path, err)
+		}
+	}
+	merged.ApplyDefaults()
+	if err = merged.Validate(); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
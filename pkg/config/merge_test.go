@@ -0,0 +1,179 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	set := func(path string, value any) func(*Config) error {
+		return func(c *Config) error { return c.SetPath(path, value) }
+	}
+	unset := func(path string) func(*Config) error {
+		return func(c *Config) error {
+			keyPath, err := c.KeyPath(path)
+			if err != nil {
+				return err
+			}
+			return c.Unset(keyPath)
+		}
+	}
+	addProduct := func(name string, enabled bool) func(*Config) error {
+		return func(c *Config) error {
+			return c.SetProduct(name, Product{Name: name, Enabled: enabled})
+		}
+	}
+
+	tests := []struct {
+		name   string
+		ours   []func(*Config) error
+		theirs []func(*Config) error
+		err    error
+		check  map[string]any
+	}{{
+		name:   "disjoint changes",
+		ours:   []func(*Config) error{set("settings.crc", true)},
+		theirs: []func(*Config) error{set("products.Developer Hub.enabled", false)},
+		check: map[string]any{
+			"settings.crc":                   true,
+			"products.Developer Hub.enabled": false,
+		},
+	}, {
+		name:   "same change on both sides",
+		ours:   []func(*Config) error{set("settings.crc", true)},
+		theirs: []func(*Config) error{set("settings.crc", true)},
+		check:  map[string]any{"settings.crc": true},
+	}, {
+		name:   "conflicting changes",
+		ours:   []func(*Config) error{set("settings.crc", true)},
+		theirs: []func(*Config) error{set("settings.crc", "yes")},
+		err:    ErrConfigConflict,
+	}, {
+		name: "nested conflicting changes",
+		ours: []func(*Config) error{
+			set("products.Developer Hub.properties.authProvider", "gitlab"),
+		},
+		theirs: []func(*Config) error{
+			unset("products.Developer Hub.properties.authProvider"),
+		},
+		err: ErrConfigConflict,
+	}, {
+		name:   "removal merged with other change",
+		ours:   []func(*Config) error{unset("settings.crc")},
+		theirs: []func(*Config) error{set("products.Developer Hub.enabled", false)},
+		check: map[string]any{
+			"settings.crc":                   nil,
+			"products.Developer Hub.enabled": false,
+		},
+	}, {
+		name:   "product added",
+		ours:   []func(*Config) error{addProduct("Pipelines", true)},
+		theirs: []func(*Config) error{set("settings.crc", true)},
+		check: map[string]any{
+			"settings.crc":               true,
+			"products.Pipelines.enabled": true,
+		},
+	}, {
+		name:   "same product added on both sides",
+		ours:   []func(*Config) error{addProduct("Pipelines", true)},
+		theirs: []func(*Config) error{addProduct("Pipelines", true)},
+		check:  map[string]any{"products.Pipelines.enabled": true},
+	}, {
+		name:   "product added on both sides with different specs",
+		ours:   []func(*Config) error{addProduct("Pipelines", true)},
+		theirs: []func(*Config) error{addProduct("Pipelines", false)},
+		err:    ErrConfigConflict,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := newTestConfig(t)
+			ours := newTestConfig(t)
+			theirs := newTestConfig(t)
+			for _, fn := range tt.ours {
+				if err := fn(ours); err != nil {
+					t.Fatalf("changing ours: %v", err)
+				}
+			}
+			for _, fn := range tt.theirs {
+				if err := fn(theirs); err != nil {
+					t.Fatalf("changing theirs: %v", err)
+				}
+			}
+			payloads := []string{base.String(), ours.String(), theirs.String()}
+			defaults := []bool{}
+			for _, c := range []*Config{base, ours, theirs} {
+				for _, p := range c.Installer.Products {
+					defaults = append(defaults, p.Namespace != nil)
+				}
+			}
+
+			merged, err := Merge(base, ours, theirs)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected error %v, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for path, expected := range tt.check {
+				value, found := lookupPath(t, merged, path)
+				if expected == nil {
+					if found {
+						t.Errorf("%s: expected removed, got %v", path, value)
+					}
+					continue
+				}
+				if value != expected {
+					t.Errorf("%s: expected %v, got %v", path, expected, value)
+				}
+			}
+
+			// The informed configurations are not modified, defaults included.
+			i := 0
+			for n, c := range []*Config{base, ours, theirs} {
+				if c.String() != payloads[n] {
+					t.Errorf("configuration %d modified:\n%s", n, c.String())
+				}
+				for _, p := range c.Installer.Products {
+					if (p.Namespace != nil) != defaults[i] {
+						t.Errorf("configuration %d: defaults changed on %q",
+							n, p.Name)
+					}
+					i++
+				}
+			}
+		})
+	}
+}
+
+// lookupPath returns the value on the dotted path, settings or products by name.
+func lookupPath(t *testing.T, c *Config, path string) (any, bool) {
+	t.Helper()
+	var node any = map[string]any{
+		"settings": map[string]any(c.Installer.Settings),
+	}
+	if name := c.PathProduct(path); name != "" {
+		p, err := c.GetProduct(name)
+		if err != nil {
+			return nil, false
+		}
+		node = map[string]any{"products": map[string]any{name: map[string]any{
+			"enabled":    p.Enabled,
+			"properties": p.Properties,
+		}}}
+	}
+	for _, k := range strings.Split(path, ".") {
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if node, ok = m[k]; !ok {
+			return nil, false
+		}
+	}
+	return node, true
+}
@@ -0,0 +1,265 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FindNode searches for a specific key within a YAML node structure.
+//
+// It traverses the YAML node structure:
+//   - If the node is a DocumentNode, it unwraps to its first content node and
+//     recurses.
+//   - If the node is a MappingNode, it first checks if the key exists directly
+//     within the current mapping. If not found, it recursively searches
+//     within the values of the current mapping.
+//   - For any other node kind, it returns an error.
+//
+// Returns the yaml.Node corresponding to the found key's value, or an error
+// if the key is not found, or the node kind is unsupported.
+func FindNode(node *yaml.Node, key string) (*yaml.Node, error) {
+	current := node
+
+	switch current.Kind {
+	case yaml.DocumentNode:
+		if len(current.Content) == 0 {
+			return nil, fmt.Errorf("empty document")
+		}
+		current = current.Content[0]
+		return FindNode(current, key)
+	case yaml.MappingNode:
+		for i := 0; i < len(current.Content); i += 2 {
+			keyNode := current.Content[i]
+			valueNode := current.Content[i+1]
+			if keyNode.Value == key {
+				return valueNode, nil
+			}
+		}
+		for i := 1; i < len(current.Content); i += 2 {
+			result, err := FindNode(current.Content[i], key)
+			if err == nil && result != nil {
+				return result, nil
+			}
+		}
+		return nil, fmt.Errorf("key %q not found", key)
+	default:
+		return nil, fmt.Errorf("cannot find config: %v", key)
+	}
+}
+
+// valueNode marshals the value to YAML and unmarshals it back into a new
+// yaml.Node, preserving the value type fidelity.
+func valueNode(key string, value any) (*yaml.Node, error) {
+	var doc yaml.Node
+	bs, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(bs, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, fmt.Errorf("invalid new value for key %q", key)
+	}
+	return doc.Content[0], nil
+}
+
+// kindName returns a human readable name for the YAML node kind.
+func kindName(kind yaml.Kind) string {
+	switch kind {
+	case yaml.DocumentNode:
+		return "document"
+	case yaml.SequenceNode:
+		return "sequence"
+	case yaml.MappingNode:
+		return "mapping"
+	case yaml.ScalarNode:
+		return "scalar"
+	case yaml.AliasNode:
+		return "alias"
+	default:
+		return fmt.Sprintf("unknown (%d)", kind)
+	}
+}
+
+// isNullNode checks whether the node is an empty (null) scalar, i.e. a key
+// without value, which can be turned into a mapping.
+func isNullNode(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+}
+
+// UpdateMappingValue updates a key's value within a YAML mapping node.
+//
+// It traverses the YAML node structure:
+//   - If the node is a DocumentNode, it delegates to its first content node.
+//     If the DocumentNode is empty, it creates a root mapping node.
+//   - If the node is a null scalar, it's turned into a mapping node.
+//   - If the node is a MappingNode, it searches for the specified key.
+//     If found, it marshals the newValue to YAML and unmarshals it back
+//     into a new yaml.Node to preserve type fidelity, then replaces the
+//     existing value node, keeping its anchor and comments. If the key is not
+//     found, the key and value are appended to the mapping.
+//   - For any other node kind, it returns an error.
+func UpdateMappingValue(node *yaml.Node, key string, newValue any) error {
+	if isNullNode(node) {
+		node.Kind = yaml.MappingNode
+		node.Tag = ""
+		node.Value = ""
+	}
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) > 0 {
+			return UpdateMappingValue(node.Content[0], key, newValue)
+		}
+		// Create root mapping.
+		mappingNode := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{}}
+		node.Content = []*yaml.Node{mappingNode}
+		return UpdateMappingValue(mappingNode, key, newValue)
+	case yaml.MappingNode:
+		newValueNode, err := valueNode(key, newValue)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < len(node.Content); i += 2 {
+			// Find existing key.
+			if node.Content[i].Value != key {
+				continue
+			}
+
+			// Replace node and preserve anchor and comments.
+			oldValue := node.Content[i+1]
+			newValueNode.Anchor = oldValue.Anchor
+			newValueNode.HeadComment = oldValue.HeadComment
+			newValueNode.LineComment = oldValue.LineComment
+			newValueNode.FootComment = oldValue.FootComment
+			node.Content[i+1] = newValueNode
+			return nil
+		}
+
+		// Create the missing key, appended to the mapping. An empty flow mapping
+		// ("{}") is turned into block style, as any other mapping.
+		if len(node.Content) == 0 {
+			node.Style &^= yaml.FlowStyle
+		}
+		keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+		node.Content = append(node.Content, keyNode, newValueNode)
+		return nil
+	default:
+		return fmt.Errorf("cannot set key %q on %s node", key, kindName(node.Kind))
+	}
+}
+
+// UpdateNestedValue updates a value deep within a YAML node structure by
+// traversing a given path of keys. The path of keys represents the hierarchy of
+// the yaml.Node structure.
+//
+// It handles different node kinds and path lengths:
+//   - If the path is empty, it returns an error.
+//   - If the path contains only one key, it delegates to UpdateMappingValue.
+//   - If the node is a DocumentNode, it unwraps to its first content node and
+//     recurses.
+//   - If the node is a null scalar, it's turned into a mapping node.
+//   - If the node is a MappingNode, it iterates through its content to find the
+//     first key in the path. If found, it recursively calls itself on the
+//     corresponding value node with the rest of the path. If the key is not
+//     found, an empty mapping is created for it.
+//   - If the node is a SequenceNode, the key must be a valid index.
+//   - For any other node kind, it returns an error, as navigation is not
+//     possible.
+func UpdateNestedValue(node *yaml.Node, keyPath []string, newValue any) error {
+	if len(keyPath) == 0 {
+		return fmt.Errorf("config path is missing")
+	}
+	if len(keyPath) == 1 {
+		return UpdateMappingValue(node, keyPath[0], newValue)
+	}
+	key := keyPath[0]
+	remainingKeys := keyPath[1:]
+
+	if isNullNode(node) {
+		node.Kind = yaml.MappingNode
+		node.Tag = ""
+		node.Value = ""
+	}
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) > 0 {
+			return UpdateNestedValue(node.Content[0], keyPath, newValue)
+		}
+		return fmt.Errorf("invalid config content")
+	case yaml.MappingNode:
+		for i := 0; i < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				return UpdateNestedValue(
+					node.Content[i+1], remainingKeys, newValue)
+			}
+		}
+		// Create the missing intermediate key as an empty mapping.
+		keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+		mappingNode := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		node.Content = append(node.Content, keyNode, mappingNode)
+		return UpdateNestedValue(mappingNode, remainingKeys, newValue)
+	case yaml.SequenceNode:
+		index, err := strconv.Atoi(key)
+		if err != nil {
+			return fmt.Errorf("invalid array index: %q", key)
+		}
+		if index < 0 || index >= len(node.Content) {
+			return fmt.Errorf("array index out of bounds: %d", index)
+		}
+		return UpdateNestedValue(node.Content[index], remainingKeys, newValue)
+	default:
+		return fmt.Errorf("cannot navigate through %s node on key %q",
+			kindName(node.Kind), key)
+	}
+}
+
+// RemoveNestedValue removes a key deep within a YAML node structure by
+// traversing a given path of keys, analogous to UpdateNestedValue. The last key
+// in the path is removed from its mapping node, or when the parent is a
+// sequence, the path index is removed from it. Comments attached to the removed
+// key are dropped along with it.
+func RemoveNestedValue(node *yaml.Node, keyPath []string) error {
+	if len(keyPath) == 0 {
+		return fmt.Errorf("config path is missing")
+	}
+	key := keyPath[0]
+	last := len(keyPath) == 1
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) > 0 {
+			return RemoveNestedValue(node.Content[0], keyPath)
+		}
+		return fmt.Errorf("invalid config content")
+	case yaml.MappingNode:
+		for i := 0; i < len(node.Content); i += 2 {
+			if node.Content[i].Value != key {
+				continue
+			}
+			if !last {
+				return RemoveNestedValue(node.Content[i+1], keyPath[1:])
+			}
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return nil
+		}
+		return fmt.Errorf("key not found: %s", key)
+	case yaml.SequenceNode:
+		index, err := strconv.Atoi(key)
+		if err != nil {
+			return fmt.Errorf("invalid array index: %q", key)
+		}
+		if index < 0 || index >= len(node.Content) {
+			return fmt.Errorf("array index out of bounds: %d", index)
+		}
+		if !last {
+			return RemoveNestedValue(node.Content[index], keyPath[1:])
+		}
+		node.Content = append(node.Content[:index], node.Content[index+1:]...)
+		return nil
+	default:
+		return fmt.Errorf("cannot navigate through node kind: %v", node.Kind)
+	}
+}
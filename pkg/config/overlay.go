@@ -0,0 +1,171 @@
+package config
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/chartfs"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProfilesDir directory on the installer filesystem with the named profiles,
+// overlays stored as "{name}.yaml".
+const ProfilesDir = "profiles"
+
+// ProfilePath returns the overlay path for the named profile.
+func ProfilePath(name string) string {
+	return path.Join(ProfilesDir, name+".yaml")
+}
+
+// productName returns the product name of a product mapping node.
+func productName(node *yaml.Node) string {
+	if name := mappingValue(node, "name"); name != nil {
+		return name.Value
+	}
+	return ""
+}
+
+// mergeProducts merges the overlay products sequence into the base sequence,
+// products are matched by name: matching products are merged, the others are
+// appended.
+func mergeProducts(base, overlay *yaml.Node) error {
+	for _, item := range overlay.Content {
+		name := productName(item)
+		if item.Kind != yaml.MappingNode || name == "" {
+			return fmt.Errorf("%w: overlay products must have a name",
+				ErrInvalidConfig)
+		}
+		var target *yaml.Node
+		for _, p := range base.Content {
+			if productName(p) == name {
+				target = p
+				break
+			}
+		}
+		if target == nil {
+			base.Content = append(base.Content, item)
+			continue
+		}
+		if err := mergeMapping(target, item, false); err != nil {
+			return fmt.Errorf("product %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// mergeMapping merges the overlay mapping node into the base mapping node, key
+// by key: mappings are merged recursively, null values remove the key, and any
+// other value replaces the base value, keeping its comments. When products is
+// set, the "products" key is merged by product name.
+func mergeMapping(base, overlay *yaml.Node, products bool) error {
+	if isNullNode(base) {
+		base.Kind = yaml.MappingNode
+		base.Tag = ""
+		base.Value = ""
+	}
+	if base.Kind != yaml.MappingNode || overlay.Kind != yaml.MappingNode {
+		return fmt.Errorf("cannot merge %s node into %s node",
+			kindName(overlay.Kind), kindName(base.Kind))
+	}
+	for i := 0; i+1 < len(overlay.Content); i += 2 {
+		key, value := overlay.Content[i], overlay.Content[i+1]
+
+		index := -1
+		for j := 0; j+1 < len(base.Content); j += 2 {
+			if base.Content[j].Value == key.Value {
+				index = j
+				break
+			}
+		}
+		switch {
+		case isNullNode(value):
+			if index >= 0 {
+				base.Content = append(base.Content[:index], base.Content[index+2:]...)
+			}
+		case index < 0:
+			base.Content = append(base.Content, key, value)
+		case products && key.Value == "products" &&
+			base.Content[index+1].Kind == yaml.SequenceNode &&
+			value.Kind == yaml.SequenceNode:
+			if err := mergeProducts(base.Content[index+1], value); err != nil {
+				return err
+			}
+		case base.Content[index+1].Kind == yaml.MappingNode &&
+			value.Kind == yaml.MappingNode:
+			if err := mergeMapping(base.Content[index+1], value, false); err != nil {
+				return fmt.Errorf("%s: %w", key.Value, err)
+			}
+		default:
+			old := base.Content[index+1]
+			if value.HeadComment == "" {
+				value.HeadComment = old.HeadComment
+			}
+			if value.LineComment == "" {
+				value.LineComment = old.LineComment
+			}
+			base.Content[index+1] = value
+		}
+	}
+	return nil
+}
+
+// Overlay merges the overlay configuration payload on top of this
+// configuration. The overlay uses the same structure, with only the settings and
+// products which change: mappings are merged key by key, a null value removes
+// the key, and products are merged by name, new products are appended. The
+// resulting configuration is validated.
+func (c *Config) Overlay(payload []byte) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(payload, &doc); err != nil {
+		return fmt.Errorf("%w: %w", ErrUnmarshalConfig, err)
+	}
+	if len(doc.Content) == 0 {
+		return ErrEmptyConfig
+	}
+	appOverlay := mappingValue(doc.Content[0], c.appName)
+	if appOverlay == nil {
+		return fmt.Errorf("%w: missing '%s' key", ErrInvalidConfig, c.appName)
+	}
+	if len(c.root.Content) == 0 {
+		return fmt.Errorf("%w: content is empty", ErrInvalidConfig)
+	}
+	appNode := mappingValue(c.root.Content[0], c.appName)
+	if appNode == nil {
+		return fmt.Errorf("%w: missing '%s' key", ErrInvalidConfig, c.appName)
+	}
+	if err := mergeMapping(appNode, appOverlay, true); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	if err := c.DecodeNode(); err != nil {
+		return err
+	}
+	c.ApplyDefaults()
+	return c.Validate()
+}
+
+// NewConfigFromFiles returns a new Config instance based on the informed base
+// file, with the overlay files merged on top of it in order. The overlays are
+// read from the local or embedded filesystem, like the base file.
+func NewConfigFromFiles(
+	cfs chartfs.Interface,
+	configPath string,
+	overlayPaths []string,
+	namespace string,
+	appName string,
+) (*Config, error) {
+	c, err := NewConfigFromFile(cfs, configPath, namespace, appName)
+	if err != nil {
+		return nil, err
+	}
+	for _, overlayPath := range overlayPaths {
+		payload, err := cfs.ReadFile(overlayPath)
+		if err != nil {
+			return nil, err
+		}
+		if err = c.Overlay(payload); err != nil {
+			return nil, fmt.Errorf("overlay %q: %w", overlayPath, err)
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,201 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/k8s"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// SecretRefKey the mapping key for secret references, a mapping with this single
+// key is replaced by the secret value.
+const SecretRefKey = "secretRef"
+
+// ErrUnresolvedReference the configuration reference can't be resolved.
+var ErrUnresolvedReference = errors.New("unresolved configuration reference")
+
+// envRefRegexp matches the environment variable references, "${env:VAR}".
+var envRefRegexp = regexp.MustCompile(`\$\{env:([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// SecretRef represents a reference to a Kubernetes secret key, informed on the
+// configuration as:
+//
+//	secretRef:
+//	  name: secret-name
+//	  key: secret-key
+//	  namespace: optional, the installer namespace by default
+type SecretRef struct {
+	Namespace string // secret namespace
+	Name      string // secret name
+	Key       string // secret data key
+}
+
+// String returns the secret reference formatted as "namespace/name[key]".
+func (s SecretRef) String() string {
+	return fmt.Sprintf("%s/%s[%s]", s.Namespace, s.Name, s.Key)
+}
+
+// asMap returns the informed value as a generic mapping, when it's a mapping.
+func asMap(value any) (map[string]any, bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		return v, true
+	case Settings:
+		return map[string]any(v), true
+	default:
+		return nil, false
+	}
+}
+
+// secretRef returns the secret reference when the value is a mapping with the
+// single "secretRef" key, describing the secret name and key.
+func secretRef(value any) (*SecretRef, bool) {
+	m, ok := asMap(value)
+	if !ok || len(m) != 1 {
+		return nil, false
+	}
+	ref, ok := asMap(m[SecretRefKey])
+	if !ok {
+		return nil, false
+	}
+	s := &SecretRef{}
+	fields := map[string]*string{
+		"namespace": &s.Namespace,
+		"name":      &s.Name,
+		"key":       &s.Key,
+	}
+	for key, v := range ref {
+		field, known := fields[key]
+		str, ok := v.(string)
+		if !known || !ok {
+			return nil, false
+		}
+		*field = str
+	}
+	return s, true
+}
+
+// IsReference checks whether the value is a secret reference, or a string with
+// environment variable references.
+func IsReference(value any) bool {
+	if str, ok := value.(string); ok {
+		return envRefRegexp.MatchString(str)
+	}
+	_, ok := secretRef(value)
+	return ok
+}
+
+// References resolves the secret and environment variable references on the
+// configuration values. The configuration stored in the cluster keeps only the
+// references, the actual values are resolved when needed, i.e. rendering the
+// values template.
+type References struct {
+	kube      k8s.Interface // kubernetes client
+	namespace string        // default secrets namespace
+
+	secrets map[types.NamespacedName]*corev1.Secret // secrets cache
+}
+
+// secretValue returns the value of the secret key referenced.
+func (r *References) secretValue(
+	ctx context.Context,
+	path string,
+	ref *SecretRef,
+) (string, error) {
+	if ref.Namespace == "" {
+		ref.Namespace = r.namespace
+	}
+	if ref.Name == "" || ref.Key == "" {
+		return "", fmt.Errorf("%w: %s: secret reference requires name and key",
+			ErrUnresolvedReference, path)
+	}
+	name := types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}
+	secret, cached := r.secrets[name]
+	if !cached {
+		var err error
+		if secret, err = k8s.GetSecret(ctx, r.kube, name); err != nil {
+			return "", fmt.Errorf("%w: %s: secret %s: %w",
+				ErrUnresolvedReference, path, ref, err)
+		}
+		r.secrets[name] = secret
+	}
+	if data, exists := secret.Data[ref.Key]; exists {
+		return string(data), nil
+	}
+	if data, exists := secret.StringData[ref.Key]; exists {
+		return data, nil
+	}
+	return "", fmt.Errorf("%w: %s: secret %s: key not found",
+		ErrUnresolvedReference, path, ref)
+}
+
+// expandEnv replaces the environment variable references on the string, all
+// referenced variables must be set.
+func expandEnv(path, str string) (string, error) {
+	var err error
+	expanded := envRefRegexp.ReplaceAllStringFunc(str, func(m string) string {
+		name := envRefRegexp.FindStringSubmatch(m)[1]
+		value, exists := os.LookupEnv(name)
+		if !exists && err == nil {
+			err = fmt.Errorf("%w: %s: environment variable %q is not set",
+				ErrUnresolvedReference, path, name)
+		}
+		return value
+	})
+	return expanded, err
+}
+
+// Resolve returns a copy of the informed value with all references resolved,
+// the path identifies the value on error messages.
+func (r *References) Resolve(
+	ctx context.Context,
+	path string,
+	value any,
+) (any, error) {
+	if ref, ok := secretRef(value); ok {
+		return r.secretValue(ctx, path, ref)
+	}
+	if m, ok := asMap(value); ok {
+		resolved := make(map[string]any, len(m))
+		for k, v := range m {
+			var err error
+			if resolved[k], err = r.Resolve(ctx, path+"."+k, v); err != nil {
+				return nil, err
+			}
+		}
+		return resolved, nil
+	}
+	switch v := value.(type) {
+	case []any:
+		resolved := make([]any, len(v))
+		for i, item := range v {
+			var err error
+			if resolved[i], err = r.Resolve(
+				ctx, fmt.Sprintf("%s.%d", path, i), item,
+			); err != nil {
+				return nil, err
+			}
+		}
+		return resolved, nil
+	case string:
+		return expandEnv(path, v)
+	default:
+		return value, nil
+	}
+}
+
+// NewReferences instantiates the references resolver, secrets without namespace
+// are looked up on the informed namespace.
+func NewReferences(kube k8s.Interface, namespace string) *References {
+	return &References{
+		kube:      kube,
+		namespace: namespace,
+		secrets:   map[types.NamespacedName]*corev1.Secret{},
+	}
+}
@@ -0,0 +1,348 @@
+package config
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/annotations"
+	"github.com/redhat-appstudio/tssc-cli/pkg/chartfs"
+	"github.com/redhat-appstudio/tssc-cli/pkg/constants"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"gopkg.in/yaml.v3"
+)
+
+// Schema represents the JSON schemas the configuration is validated against:
+// the settings schema provided by the installer, and the properties schema
+// shipped by each product Helm chart.
+type Schema struct {
+	settings *jsonschema.Schema            // settings schema
+	products map[string]*jsonschema.Schema // properties schema by product name
+	names    []string                      // product names provided by charts
+}
+
+// ErrSchemaViolation the configuration doesn't comply with the JSON schemas.
+var ErrSchemaViolation = errors.New("configuration schema violation")
+
+// ErrUnknownProduct the product is not provided by any installer Helm chart.
+var ErrUnknownProduct = errors.New("unknown product")
+
+// Violation represents a single schema violation, located in the YAML payload.
+type Violation struct {
+	Line    int    // YAML line number
+	Column  int    // YAML column number
+	Path    string // dotted configuration path
+	Message string // violation description
+}
+
+// String returns the violation formatted with its position.
+func (v Violation) String() string {
+	return fmt.Sprintf("line %d, column %d: %s: %s",
+		v.Line, v.Column, v.Path, v.Message)
+}
+
+// printer renders the schema violation messages.
+var printer = message.NewPrinter(language.English)
+
+// compileSchema compiles the informed JSON schema payload, the name identifies
+// the schema resource on the installer filesystem.
+func compileSchema(name string, payload []byte) (*jsonschema.Schema, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("parsing schema %q: %w", name, err)
+	}
+	url := "file:///" + name
+	c := jsonschema.NewCompiler()
+	if err = c.AddResource(url, doc); err != nil {
+		return nil, fmt.Errorf("loading schema %q: %w", name, err)
+	}
+	sch, err := c.Compile(url)
+	if err != nil {
+		return nil, fmt.Errorf("compiling schema %q: %w", name, err)
+	}
+	return sch, nil
+}
+
+// mappingValue returns the value node for the key on the mapping node, or nil
+// when the key is not present.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// locateNode navigates the node following the JSON instance location, returning
+// the deepest node found. Unknown properties are located on their key node.
+func locateNode(
+	node *yaml.Node,
+	location []string,
+	errKind jsonschema.ErrorKind,
+) *yaml.Node {
+	for _, token := range location {
+		switch node.Kind {
+		case yaml.MappingNode:
+			next := mappingValue(node, token)
+			if next == nil {
+				return node
+			}
+			node = next
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(node.Content) {
+				return node
+			}
+			node = node.Content[i]
+		default:
+			return node
+		}
+	}
+	if ap, ok := errKind.(*kind.AdditionalProperties); ok &&
+		node.Kind == yaml.MappingNode && len(ap.Properties) > 0 {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == ap.Properties[0] {
+				return node.Content[i]
+			}
+		}
+	}
+	return node
+}
+
+// leafErrors collects the innermost validation errors, which carry the actual
+// reasons for the violation.
+func leafErrors(ve *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(ve.Causes) == 0 {
+		return []*jsonschema.ValidationError{ve}
+	}
+	leaves := []*jsonschema.ValidationError{}
+	for _, cause := range ve.Causes {
+		leaves = append(leaves, leafErrors(cause)...)
+	}
+	return leaves
+}
+
+// referenced checks whether the JSON instance location, or any of its parents,
+// holds a configuration reference. References are only resolved on rendering,
+// therefore the schema constraints don't apply to them.
+func referenced(value any, location []string) bool {
+	for _, token := range location {
+		switch v := value.(type) {
+		case map[string]any:
+			value = v[token]
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(v) {
+				return false
+			}
+			value = v[i]
+		default:
+			return false
+		}
+		if IsReference(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateNode validates the YAML node against the schema, the violations are
+// located on the node, or on the parent when the node is missing (nil). The
+// path prefixes the violation paths. Violations on references are ignored.
+func validateNode(
+	sch *jsonschema.Schema,
+	node *yaml.Node,
+	parent *yaml.Node,
+	path string,
+) ([]Violation, error) {
+	// The YAML node is normalized into a JSON instance, as the schema validator
+	// expects, a missing or empty node is an empty object.
+	var value any = map[string]any{}
+	if node != nil && !isNullNode(node) {
+		if err := node.Decode(&value); err != nil {
+			return nil, fmt.Errorf("decoding %q: %w", path, err)
+		}
+	} else {
+		node = parent
+	}
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("encoding %q: %w", path, err)
+	}
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("encoding %q: %w", path, err)
+	}
+
+	err = sch.Validate(instance)
+	if err == nil {
+		return nil, nil
+	}
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		return nil, err
+	}
+	violations := []Violation{}
+	for _, leaf := range leafErrors(ve) {
+		if referenced(value, leaf.InstanceLocation) {
+			continue
+		}
+		n := locateNode(node, leaf.InstanceLocation, leaf.ErrorKind)
+		violations = append(violations, Violation{
+			Line:    n.Line,
+			Column:  n.Column,
+			Path:    strings.Join(append([]string{path}, leaf.InstanceLocation...), "."),
+			Message: leaf.ErrorKind.LocalizedString(printer),
+		})
+	}
+	return violations, nil
+}
+
+// Violations validates the configuration settings, and the properties of the
+// products with a schema, returning the violations sorted by position. The
+// positions refer to the YAML payload the configuration is loaded from.
+func (s *Schema) Violations(cfg *Config) ([]Violation, error) {
+	if len(cfg.root.Content) == 0 {
+		return nil, fmt.Errorf("%w: content is empty", ErrInvalidConfig)
+	}
+	appNode := mappingValue(cfg.root.Content[0], cfg.appName)
+	if appNode == nil {
+		return nil, fmt.Errorf("%w: missing %q key", ErrInvalidConfig, cfg.appName)
+	}
+
+	violations := []Violation{}
+	if s.settings != nil {
+		v, err := validateNode(
+			s.settings, mappingValue(appNode, "settings"), appNode, "settings")
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, v...)
+	}
+
+	productsNode := mappingValue(appNode, "products")
+	for i, p := range cfg.Installer.Products {
+		sch, ok := s.products[p.Name]
+		if !ok || productsNode == nil || i >= len(productsNode.Content) {
+			continue
+		}
+		productNode := productsNode.Content[i]
+		v, err := validateNode(
+			sch,
+			mappingValue(productNode, "properties"),
+			productNode,
+			fmt.Sprintf("products.%s.properties", p.Name),
+		)
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, v...)
+	}
+
+	slices.SortStableFunc(violations, func(a, b Violation) int {
+		return cmp.Or(cmp.Compare(a.Line, b.Line), cmp.Compare(a.Column, b.Column))
+	})
+	return violations, nil
+}
+
+// ValidatePayload validates the configuration as it's serialized to the cluster,
+// so the violations are located on the stored payload instead of the in-memory
+// changes.
+func (s *Schema) ValidatePayload(cfg *Config) error {
+	payload, err := cfg.MarshalYAML()
+	if err != nil {
+		return err
+	}
+	reloaded, err := NewConfigFromBytes(payload, cfg.namespace, cfg.appName)
+	if err != nil {
+		return err
+	}
+	return s.Validate(reloaded)
+}
+
+// ValidateProductName asserts the product name is declared by an installer Helm
+// chart, the name must match exactly.
+func (s *Schema) ValidateProductName(name string) error {
+	if slices.Contains(s.names, name) {
+		return nil
+	}
+	return fmt.Errorf("%w: %q, the installer charts provide: %s",
+		ErrUnknownProduct, name, strings.Join(s.names, ", "))
+}
+
+// Validate validates the configuration against the schemas, all violations are
+// reported on the returned error.
+func (s *Schema) Validate(cfg *Config) error {
+	violations, err := s.Violations(cfg)
+	if err != nil {
+		return err
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	lines := make([]string, 0, len(violations))
+	for _, v := range violations {
+		lines = append(lines, "  - "+v.String())
+	}
+	return fmt.Errorf("%w:\n%s", ErrSchemaViolation, strings.Join(lines, "\n"))
+}
+
+// NewSchema loads the settings schema, and the product properties schemas from
+// the product Helm charts on the installer filesystem. The schemas are
+// optional, settings and products without a schema are not validated.
+func NewSchema(cfs chartfs.Interface) (*Schema, error) {
+	s := &Schema{products: map[string]*jsonschema.Schema{}}
+
+	payload, err := cfs.ReadFile(constants.SettingsSchemaFilename)
+	switch {
+	case err == nil:
+		if s.settings, err = compileSchema(
+			constants.SettingsSchemaFilename, payload,
+		); err != nil {
+			return nil, err
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, err
+	}
+
+	charts, err := cfs.GetAllCharts()
+	if err != nil {
+		return nil, err
+	}
+	for _, hc := range charts {
+		if hc.Metadata == nil {
+			continue
+		}
+		name := hc.Metadata.Annotations[annotations.ProductName]
+		if name == "" {
+			continue
+		}
+		s.names = append(s.names, name)
+		for _, f := range hc.Files {
+			if f.Name != constants.PropertiesSchemaFilename {
+				continue
+			}
+			if s.products[name], err = compileSchema(
+				hc.Name()+"/"+f.Name, f.Data,
+			); err != nil {
+				return nil, err
+			}
+		}
+	}
+	slices.Sort(s.names)
+	return s, nil
+}
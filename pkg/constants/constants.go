@@ -0,0 +1,19 @@
+package constants
+
+const (
+	// ConfigFilename is the installer configuration file.
+	ConfigFilename = "config.yaml"
+
+	// ValuesFilename is the values template file.
+	ValuesFilename = "values.yaml.tpl"
+
+	// SettingsSchemaFilename is the JSON schema for the configuration settings.
+	SettingsSchemaFilename = "settings.schema.json"
+
+	// PropertiesSchemaFilename is the JSON schema for the product properties,
+	// shipped by each product Helm chart.
+	PropertiesSchemaFilename = "properties.schema.json"
+
+	// ValuesTemplateFlag flag name for the values template file.
+	ValuesTemplateFlag = "values-template"
+)
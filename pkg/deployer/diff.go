@@ -0,0 +1,401 @@
+package deployer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ResourceChange describes how a resource changes between two manifests.
+type ResourceChange string
+
+const (
+	// ResourceAdded the resource is only present on the target manifest.
+	ResourceAdded ResourceChange = "added"
+	// ResourceChanged the resource is present on both manifests, and differs.
+	ResourceChanged ResourceChange = "changed"
+	// ResourceRemoved the resource is only present on the source manifest.
+	ResourceRemoved ResourceChange = "removed"
+)
+
+// redacted replaces the Secret values on the manifest diffs.
+const redacted = "(redacted)"
+
+// ResourceDiff represents the difference of a single resource between two
+// manifests, as an unified diff.
+type ResourceDiff struct {
+	Change   ResourceChange `json:"change"`   // kind of change
+	Resource string         `json:"resource"` // kind, namespace and name
+	Diff     string         `json:"diff"`     // unified diff
+}
+
+// ManifestDiff represents the differences between the resources of a source
+// manifest, e.g. the deployed release, and a target manifest, e.g. the rendered
+// chart. Only the resources which differ are listed, in the target order.
+type ManifestDiff struct {
+	Release   string         `json:"release"`   // helm release name
+	Namespace string         `json:"namespace"` // release namespace
+	From      string         `json:"from"`      // source description
+	To        string         `json:"to"`        // target description
+	Resources []ResourceDiff `json:"resources"` // changed resources
+}
+
+// HasChanges checks whether the manifests differ.
+func (m *ManifestDiff) HasChanges() bool {
+	return len(m.Resources) > 0
+}
+
+// Count returns the number of resources with the informed change.
+func (m *ManifestDiff) Count(change ResourceChange) int {
+	count := 0
+	for _, r := range m.Resources {
+		if r.Change == change {
+			count++
+		}
+	}
+	return count
+}
+
+// Summary returns a single line summary of the changes.
+func (m *ManifestDiff) Summary() string {
+	return fmt.Sprintf("%d added, %d changed, %d removed",
+		m.Count(ResourceAdded), m.Count(ResourceChanged), m.Count(ResourceRemoved))
+}
+
+// PrintText prints the unified diff of each resource to the writer, followed by
+// the summary.
+func (m *ManifestDiff) PrintText(w io.Writer) {
+	fmt.Fprintf(w, "# Comparing %s with %s:\n", m.From, m.To)
+	for _, r := range m.Resources {
+		fmt.Fprintf(w, "\n# %s (%s)\n%s", r.Resource, r.Change, r.Diff)
+	}
+	fmt.Fprintf(w, "\n# %s/%s: %s\n", m.Namespace, m.Release, m.Summary())
+}
+
+// PrintJSON prints the manifest differences to the writer as JSON.
+func (m *ManifestDiff) PrintJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
+
+// object represents a Kubernetes resource parsed from a manifest.
+type object struct {
+	key     string         // kind, namespace and name
+	ref     objectRef      // resource reference
+	payload map[string]any // resource payload
+}
+
+// objectRef identifies a resource on a manifest.
+type objectRef struct {
+	APIVersion string // resource API version
+	Kind       string // resource kind
+	Namespace  string // resource namespace, may be empty
+	Name       string // resource name
+}
+
+// isSecret checks whether the resource is a core Secret.
+func (r objectRef) isSecret() bool {
+	return r.Kind == "Secret" && r.APIVersion == "v1"
+}
+
+// newObject instantiates the object from the resource payload, the key is the
+// resource group and kind, namespace and name, e.g. "Deployment.apps ns/name".
+func newObject(payload map[string]any) object {
+	str := func(m map[string]any, key string) string {
+		s, _ := m[key].(string)
+		return s
+	}
+	metadata, _ := payload["metadata"].(map[string]any)
+	ref := objectRef{
+		APIVersion: str(payload, "apiVersion"),
+		Kind:       str(payload, "kind"),
+		Namespace:  str(metadata, "namespace"),
+		Name:       str(metadata, "name"),
+	}
+	gk := schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind).GroupKind()
+	key := fmt.Sprintf("%s %s", gk.String(), ref.Name)
+	if ref.Namespace != "" {
+		key = fmt.Sprintf("%s %s/%s", gk.String(), ref.Namespace, ref.Name)
+	}
+	return object{key: key, ref: ref, payload: payload}
+}
+
+// parseManifest parses the resources of the manifest, multiple YAML documents,
+// in order. Empty documents are skipped.
+func parseManifest(manifest string) ([]object, error) {
+	objects := []object{}
+	dec := yaml.NewDecoder(bytes.NewBufferString(manifest))
+	for {
+		var payload map[string]any
+		err := dec.Decode(&payload)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(payload) == 0 {
+			continue
+		}
+		objects = append(objects, newObject(payload))
+	}
+	return objects, nil
+}
+
+// redactSecret returns a copy of the Secret payload with its values redacted,
+// values which differ from the other Secret, when informed, are flagged.
+func redactSecret(payload, other map[string]any) map[string]any {
+	redactedPayload := make(map[string]any, len(payload))
+	for k, v := range payload {
+		redactedPayload[k] = v
+	}
+	for _, field := range []string{"data", "stringData"} {
+		values, ok := payload[field].(map[string]any)
+		if !ok {
+			continue
+		}
+		otherValues, _ := other[field].(map[string]any)
+		masked := make(map[string]any, len(values))
+		for k, v := range values {
+			masked[k] = redacted
+			if other != nil {
+				if o, exists := otherValues[k]; exists && !reflect.DeepEqual(o, v) {
+					masked[k] = redacted + " changed"
+				}
+			}
+		}
+		redactedPayload[field] = masked
+	}
+	return redactedPayload
+}
+
+// marshal renders the payload as YAML lines with sorted keys, none for nil.
+func marshal(payload map[string]any) ([]string, error) {
+	if payload == nil {
+		return []string{}, nil
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(payload); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return difflib.SplitLines(strings.TrimSuffix(buf.String(), "\n")), nil
+}
+
+// unifiedDiff returns the unified diff of the source and target payloads, Secret
+// values are redacted. The source values are never shown, only whether they
+// changed on the target.
+func unifiedDiff(
+	ref objectRef,
+	from, to map[string]any,
+	fromFile, toFile string,
+) (string, error) {
+	if ref.isSecret() {
+		var fromRedacted map[string]any
+		if from != nil {
+			fromRedacted = redactSecret(from, nil)
+		}
+		if to != nil {
+			to = redactSecret(to, from)
+		}
+		from = fromRedacted
+	}
+	a, err := marshal(from)
+	if err != nil {
+		return "", err
+	}
+	b, err := marshal(to)
+	if err != nil {
+		return "", err
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        a,
+		B:        b,
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  3,
+	})
+}
+
+// NewManifestDiff compares the resources of the source and target manifests,
+// matched by group, kind, namespace and name. The labels describe each side on
+// the output, e.g. "release revision 3" and "rendered chart".
+func NewManifestDiff(
+	release, namespace string,
+	source, target string,
+	from, to string,
+) (*ManifestDiff, error) {
+	sourceObjects, err := parseManifest(source)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s manifest: %w", from, err)
+	}
+	targetObjects, err := parseManifest(target)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s manifest: %w", to, err)
+	}
+
+	m := &ManifestDiff{
+		Release:   release,
+		Namespace: namespace,
+		From:      from,
+		To:        to,
+		Resources: []ResourceDiff{},
+	}
+	indexed := map[string]object{}
+	for _, o := range sourceObjects {
+		indexed[o.key] = o
+	}
+	seen := map[string]bool{}
+	for _, t := range targetObjects {
+		seen[t.key] = true
+		s, exists := indexed[t.key]
+		change := ResourceChanged
+		var payload map[string]any
+		if exists {
+			if reflect.DeepEqual(s.payload, t.payload) {
+				continue
+			}
+			payload = s.payload
+		} else {
+			change = ResourceAdded
+		}
+		if err = m.add(t.key, change, t.ref, payload, t.payload); err != nil {
+			return nil, err
+		}
+	}
+	for _, s := range sourceObjects {
+		if seen[s.key] {
+			continue
+		}
+		if err = m.add(s.key, ResourceRemoved, s.ref, s.payload, nil); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// add records the resource change, with the unified diff of its payloads.
+func (m *ManifestDiff) add(
+	key string,
+	change ResourceChange,
+	ref objectRef,
+	from, to map[string]any,
+) error {
+	diff, err := unifiedDiff(ref, from, to, m.From, m.To)
+	if err != nil {
+		return fmt.Errorf("comparing %s: %w", key, err)
+	}
+	m.Resources = append(m.Resources, ResourceDiff{
+		Change:   change,
+		Resource: key,
+		Diff:     diff,
+	})
+	return nil
+}
+
+// prune returns the live value reduced to the fields present on the desired
+// value, recursively, dropping the fields managed by the cluster, e.g. status
+// and defaults. Lists are pruned item by item when both have the same length.
+func prune(live, desired any) any {
+	switch d := desired.(type) {
+	case map[string]any:
+		l, ok := live.(map[string]any)
+		if !ok {
+			return live
+		}
+		pruned := make(map[string]any, len(d))
+		for k, v := range d {
+			if lv, exists := l[k]; exists {
+				pruned[k] = prune(lv, v)
+			}
+		}
+		return pruned
+	case []any:
+		l, ok := live.([]any)
+		if !ok || len(l) != len(d) {
+			return live
+		}
+		pruned := make([]any, len(l))
+		for i := range l {
+			pruned[i] = prune(l[i], d[i])
+		}
+		return pruned
+	default:
+		return normalize(live)
+	}
+}
+
+// normalize converts the numeric values decoded from JSON to the types decoded
+// from YAML, so equal values compare equal.
+func normalize(v any) any {
+	switch n := v.(type) {
+	case int64:
+		return int(n)
+	case float64:
+		if n == float64(int(n)) {
+			return int(n)
+		}
+	}
+	return v
+}
+
+// NewLiveDiff compares the resources of the target manifest with the live
+// objects retrieved by the informed function, which returns nil for missing
+// objects. The live objects are pruned to the fields on the manifest, thus only
+// drift on the fields managed by the chart is shown.
+func NewLiveDiff(
+	release, namespace string,
+	target string,
+	to string,
+	get func(apiVersion, kind, namespace, name string) (map[string]any, error),
+) (*ManifestDiff, error) {
+	targetObjects, err := parseManifest(target)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s manifest: %w", to, err)
+	}
+
+	m := &ManifestDiff{
+		Release:   release,
+		Namespace: namespace,
+		From:      "live objects",
+		To:        to,
+		Resources: []ResourceDiff{},
+	}
+	for _, t := range targetObjects {
+		ns := t.ref.Namespace
+		if ns == "" {
+			ns = namespace
+		}
+		live, err := get(t.ref.APIVersion, t.ref.Kind, ns, t.ref.Name)
+		if err != nil {
+			return nil, fmt.Errorf("retrieving %s: %w", t.key, err)
+		}
+		change := ResourceChanged
+		var payload map[string]any
+		if live == nil {
+			change = ResourceAdded
+		} else {
+			payload, _ = prune(live, t.payload).(map[string]any)
+			if reflect.DeepEqual(payload, t.payload) {
+				continue
+			}
+		}
+		if err = m.add(t.key, change, t.ref, payload, t.payload); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
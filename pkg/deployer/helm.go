@@ -0,0 +1,604 @@
+package deployer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/annotations"
+	"github.com/redhat-appstudio/tssc-cli/pkg/flags"
+	"github.com/redhat-appstudio/tssc-cli/pkg/k8s"
+	"github.com/redhat-appstudio/tssc-cli/pkg/monitor"
+	"github.com/redhat-appstudio/tssc-cli/pkg/printer"
+
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// Helm represents the Helm support for the installer. It's responsible for
+// running the Helm related actions.
+type Helm struct {
+	logger *slog.Logger // application logger
+	out    io.Writer    // release information output
+	flags  *flags.Flags // global flags
+
+	kube      k8s.Interface         // kubernetes client
+	chart     *chart.Chart          // helm chart instance
+	namespace string                // kubernetes namespace
+	instance  string                // installation namespace, owns the release
+	actionCfg *action.Configuration // helm action configuration
+
+	release      *release.Release // helm chart release
+	attempted    bool             // install or upgrade attempted
+	firstInstall bool             // the release didn't exist before
+	anyPending   bool             // recovers pending releases regardless
+}
+
+// ErrInstallFailed when the Helm chart installation fails.
+var ErrInstallFailed = errors.New("install failed")
+
+// ErrUpgradeFailed when the Helm chart upgrade fails.
+var ErrUpgradeFailed = errors.New("upgrade failed")
+
+// ErrReleaseOwnership when the Helm release belongs to another installation.
+var ErrReleaseOwnership = errors.New("release owned by another instance")
+
+// ErrOperationInProgress when the Helm release is pending, and may still be
+// changed by another deployment.
+var ErrOperationInProgress = errors.New("another operation is in progress")
+
+// Failure policies, the action taken on a release which failed to deploy or to
+// verify.
+const (
+	// FailurePolicyLeave leaves the release as it is, failed or pending.
+	FailurePolicyLeave = "leave"
+	// FailurePolicyRollback rolls back the release to the last good revision.
+	FailurePolicyRollback = "rollback"
+	// FailurePolicyUninstallIfFirstInstall uninstalls the release when it didn't
+	// exist before, otherwise rolls it back.
+	FailurePolicyUninstallIfFirstInstall = "uninstall-if-first-install"
+)
+
+// FailurePolicies the supported failure policies.
+var FailurePolicies = []string{
+	FailurePolicyLeave,
+	FailurePolicyRollback,
+	FailurePolicyUninstallIfFirstInstall,
+}
+
+// ErrInvalidFailurePolicy the failure policy is not supported.
+var ErrInvalidFailurePolicy = errors.New("invalid failure policy")
+
+// ValidateFailurePolicy asserts the informed failure policy is supported.
+func ValidateFailurePolicy(policy string) error {
+	if slices.Contains(FailurePolicies, policy) {
+		return nil
+	}
+	return fmt.Errorf("%w %q, expected one of: %s",
+		ErrInvalidFailurePolicy, policy, strings.Join(FailurePolicies, ", "))
+}
+
+// SetRecoverPending recovers the releases left pending regardless of how long
+// ago they were changed, otherwise only the releases pending for longer than
+// the timeout are recovered.
+func (h *Helm) SetRecoverPending(recoverPending bool) {
+	h.anyPending = recoverPending
+}
+
+// labels returns the custom labels recorded on the Helm release, identifying the
+// installation which owns the release.
+func (h *Helm) labels() map[string]string {
+	return map[string]string{annotations.Instance: h.instance}
+}
+
+// printRelease prints the Helm release information.
+func (h *Helm) printRelease(rel *release.Release) {
+	// In debug mode, print the configuration values using key-value pairs.
+	if !h.flags.DryRun && h.flags.Debug {
+		printer.ValuesPrinter(h.out, "Config", rel.Config)
+	}
+	printer.HelmReleasePrinter(h.out, rel)
+	// Print extended release information only in dry-run or debug mode. This
+	// allows rendering chart templates (dry-run) while inspecting the release
+	// manifests.
+	if h.flags.DryRun || h.flags.Debug {
+		printer.HelmExtendedReleasePrinter(h.out, rel)
+	}
+	printer.HelmReleaseNotesPrinter(h.out, rel)
+}
+
+// helmInstall equivalent to "helm install" command, on dry-run the chart is only
+// rendered.
+func (h *Helm) helmInstall(
+	ctx context.Context,
+	vals chartutil.Values,
+	dryRun bool,
+) (*release.Release, error) {
+	c := action.NewInstall(h.actionCfg)
+	c.GenerateName = false
+	c.Namespace = h.namespace
+	c.ReleaseName = h.chart.Name()
+	c.Timeout = h.flags.Timeout
+	c.Labels = h.labels()
+
+	c.DryRun = dryRun
+	c.ClientOnly = dryRun
+	if dryRun {
+		c.DryRunOption = "server"
+	}
+
+	rel, err := c.RunWithContext(ctx, h.chart, vals)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInstallFailed, err.Error())
+	}
+	return rel, nil
+}
+
+// helmUpgrade equivalent to "helm upgrade" command, on dry-run the chart is only
+// rendered.
+func (h *Helm) helmUpgrade(
+	ctx context.Context,
+	vals chartutil.Values,
+	dryRun bool,
+) (*release.Release, error) {
+	c := action.NewUpgrade(h.actionCfg)
+	c.Namespace = h.namespace
+	c.Timeout = h.flags.Timeout
+	c.Labels = h.labels()
+
+	c.DryRun = dryRun
+	if dryRun {
+		c.DryRunOption = "server"
+	}
+
+	rel, err := c.RunWithContext(ctx, h.chart.Name(), h.chart, vals)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUpgradeFailed, err.Error())
+	}
+	return rel, err
+}
+
+// blocking returns the error which prevents changing the latest release
+// revision: the release belongs to another installation, or it's pending and
+// was changed within the timeout, thus it may still be deployed by another
+// process. Returns nil otherwise.
+func (h *Helm) blocking(latest *release.Release) error {
+	if latest == nil {
+		return nil
+	}
+	if owner := latest.Labels[annotations.Instance]; owner != "" &&
+		owner != h.instance {
+		return fmt.Errorf("%w: release %q in %q belongs to %q",
+			ErrReleaseOwnership, h.chart.Name(), h.namespace, owner)
+	}
+	if latest.Info == nil || !latest.Info.Status.IsPending() || h.anyPending {
+		return nil
+	}
+	if time.Since(latest.Info.LastDeployed.Time) < h.flags.Timeout {
+		return fmt.Errorf(
+			"%w: release %q in %q is %s since %s, retry after the "+
+				"timeout (%s) or use --recover-pending",
+			ErrOperationInProgress,
+			h.chart.Name(),
+			h.namespace,
+			latest.Info.Status,
+			latest.Info.LastDeployed.UTC().Format(time.RFC3339),
+			h.flags.Timeout,
+		)
+	}
+	return nil
+}
+
+// Deploy deploys the Helm chart (Dependency) on the cluster. It checks if the
+// release is already installed in order to use the proper helm-client (action),
+// releases owned by another installation are not upgraded.
+func (h *Helm) Deploy(ctx context.Context, vals chartutil.Values) error {
+	c := action.NewHistory(h.actionCfg)
+	c.Max = 1
+
+	h.logger.Debug("Checking if release exists on the cluster")
+	history, err := c.Run(h.chart.Name())
+	if err == nil && !h.flags.DryRun {
+		// A release left pending by an interrupted deployment blocks any
+		// further operation, thus it's recovered first. A release changed
+		// within the timeout may still be deployed by another process.
+		if latest := Latest(history); latest != nil && latest.Info != nil &&
+			latest.Info.Status.IsPending() {
+			if err = h.blocking(latest); err != nil {
+				return err
+			}
+			if err = h.recoverPending(); err != nil {
+				return err
+			}
+			history, err = c.Run(h.chart.Name())
+		}
+	}
+	h.firstInstall = errors.Is(err, driver.ErrReleaseNotFound)
+	if h.firstInstall {
+		h.logger.Info("Installing Helm Chart...")
+		h.attempted = true
+		h.release, err = h.helmInstall(ctx, vals, h.flags.DryRun)
+	} else {
+		if owner := Owner(history); owner != "" && owner != h.instance {
+			return fmt.Errorf("%w: release %q in %q belongs to %q",
+				ErrReleaseOwnership, h.chart.Name(), h.namespace, owner)
+		}
+		h.logger.Info("Upgrading Helm Chart...")
+		h.attempted = true
+		h.release, err = h.helmUpgrade(ctx, vals, h.flags.DryRun)
+	}
+	if err != nil {
+		return err
+	}
+	h.printRelease(h.release)
+	return nil
+}
+
+// lastGoodRevision returns the latest successfully deployed revision of the
+// release history, older than the informed revision when not zero. Returns zero
+// when there's none.
+func lastGoodRevision(history []*release.Release, before int) int {
+	revision := 0
+	for _, rel := range history {
+		if rel.Info == nil || rel.Version <= revision ||
+			(before > 0 && rel.Version >= before) {
+			continue
+		}
+		switch rel.Info.Status {
+		case release.StatusDeployed, release.StatusSuperseded:
+			revision = rel.Version
+		}
+	}
+	return revision
+}
+
+// rollback equivalent to "helm rollback", it rolls back the release to the
+// informed revision and waits for its resources to be ready.
+func (h *Helm) rollback(revision int) error {
+	h.logger.Info("Rolling back the Helm release...", "revision", revision)
+	c := action.NewRollback(h.actionCfg)
+	c.Version = revision
+	c.Timeout = h.flags.Timeout
+	c.Wait = true
+	c.CleanupOnFail = true
+	if err := c.Run(h.chart.Name()); err != nil {
+		return fmt.Errorf("rolling back release %q to revision %d: %w",
+			h.chart.Name(), revision, err)
+	}
+	return nil
+}
+
+// uninstall removes the release, without waiting for its resources.
+func (h *Helm) uninstall() error {
+	h.logger.Info("Uninstalling the Helm release...")
+	c := action.NewUninstall(h.actionCfg)
+	c.Timeout = h.flags.Timeout
+	if _, err := c.Run(h.chart.Name()); err != nil {
+		return fmt.Errorf("uninstalling release %q: %w", h.chart.Name(), err)
+	}
+	return nil
+}
+
+// recoverPending recovers a release left pending by an interrupted deployment,
+// which otherwise fails with "another operation is in progress". A release
+// without any good revision, e.g. a pending first install, is uninstalled, the
+// others are rolled back to the last good revision.
+func (h *Helm) recoverPending() error {
+	history, err := action.NewHistory(h.actionCfg).Run(h.chart.Name())
+	if err != nil {
+		return fmt.Errorf("inspecting release %q: %w", h.chart.Name(), err)
+	}
+	latest := Latest(history)
+	h.logger.Warn("Recovering the Helm release left pending",
+		"revision", latest.Version, "status", latest.Info.Status)
+	if revision := lastGoodRevision(history, 0); revision > 0 {
+		return h.rollback(revision)
+	}
+	return h.uninstall()
+}
+
+// Recover applies the failure policy on the release, after Deploy or Verify has
+// failed. With "rollback" the release is rolled back to the last good revision,
+// a failed first install has none and is left as it is. With
+// "uninstall-if-first-install" a failed first install is uninstalled, otherwise
+// the release is rolled back. Nothing is done on dry-run, or when Deploy failed
+// before installing or upgrading the release.
+func (h *Helm) Recover(policy string) error {
+	if err := ValidateFailurePolicy(policy); err != nil {
+		return err
+	}
+	if h.flags.DryRun || !h.attempted || policy == FailurePolicyLeave {
+		return nil
+	}
+	history, err := action.NewHistory(h.actionCfg).Run(h.chart.Name())
+	if errors.Is(err, driver.ErrReleaseNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("inspecting release %q: %w", h.chart.Name(), err)
+	}
+	if h.firstInstall && policy == FailurePolicyUninstallIfFirstInstall {
+		return h.uninstall()
+	}
+	// The revision deployed by this run, when any, is the one which failed
+	// verification, thus not a good revision.
+	before := 0
+	if h.release != nil {
+		before = h.release.Version
+	}
+	revision := lastGoodRevision(history, before)
+	if revision == 0 {
+		h.logger.Warn("No good revision to roll back to, leaving the release",
+			"policy", policy)
+		return nil
+	}
+	return h.rollback(revision)
+}
+
+// Diff compares the chart rendered with the informed values, as a dry-run
+// install or upgrade, with the manifest of the deployed release. When live is
+// set, the rendered resources are compared with the live objects as well. The
+// cluster is not changed.
+func (h *Helm) Diff(
+	ctx context.Context,
+	vals chartutil.Values,
+	live bool,
+) ([]*ManifestDiff, error) {
+	deployed, err := action.NewGet(h.actionCfg).Run(h.chart.Name())
+	if err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+		return nil, fmt.Errorf("inspecting release %q: %w", h.chart.Name(), err)
+	}
+
+	source, from := "", "release (not installed)"
+	var rendered *release.Release
+	if deployed == nil {
+		rendered, err = h.helmInstall(ctx, vals, true)
+	} else {
+		source = deployed.Manifest
+		from = fmt.Sprintf("release revision %d", deployed.Version)
+		rendered, err = h.helmUpgrade(ctx, vals, true)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	const to = "rendered chart"
+	diffs := []*ManifestDiff{}
+	d, err := NewManifestDiff(
+		h.chart.Name(), h.namespace, source, rendered.Manifest, from, to)
+	if err != nil {
+		return nil, err
+	}
+	diffs = append(diffs, d)
+	if !live {
+		return diffs, nil
+	}
+
+	h.logger.Debug("Comparing the rendered chart with the live objects")
+	d, err = NewLiveDiff(h.chart.Name(), h.namespace, rendered.Manifest, to,
+		func(apiVersion, kind, namespace, name string) (map[string]any, error) {
+			obj, err := k8s.GetObject(ctx, h.kube, &corev1.ObjectReference{
+				APIVersion: apiVersion,
+				Kind:       kind,
+				Namespace:  namespace,
+				Name:       name,
+			})
+			if err != nil || obj == nil {
+				return nil, err
+			}
+			return obj.Object, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return append(diffs, d), nil
+}
+
+// Verify equivalent to "helm test", it checks whether the release is correctly
+// deployed by running chart tests and waiting for successful result.
+func (h *Helm) Verify() error {
+	if h.flags.DryRun {
+		h.logger.Debug("Dry-run mode enabled, skipping verification")
+		return nil
+	}
+
+	h.logger.Debug("Verifying the release...")
+	c := action.NewReleaseTesting(h.actionCfg)
+	c.Namespace = h.namespace
+
+	_, err := c.Run(h.chart.Name())
+	if err != nil {
+		return err
+	}
+	h.logger.Info("Release verified!")
+	return nil
+}
+
+// VerifyWithRetry attempts to verify the Helm deployment multiple times with a
+// delay between retries.
+func (h *Helm) VerifyWithRetry() error {
+	var err error
+	retries := 3
+	for i := 1; i <= retries; i++ {
+		err = h.Verify()
+		if err == nil || i == retries {
+			break
+		}
+		time.Sleep(time.Minute)
+	}
+	return err
+}
+
+// VisitReleaseResources collects the resources created by the Helm chart release.
+func (h *Helm) VisitReleaseResources(
+	ctx context.Context,
+	m monitor.Interface,
+) error {
+	releasedResources, err := h.actionCfg.KubeClient.Build(
+		bytes.NewBufferString(h.release.Manifest), true)
+	if err != nil {
+		return err
+	}
+	return releasedResources.Visit(func(r *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+		return m.Collect(ctx, r)
+	})
+}
+
+// Uninstall equivalent to "helm uninstall" command, it removes the chart release
+// and waits for its resources to be deleted, including their finalizers. A
+// missing release is skipped, releases owned by another installation are not
+// removed.
+func (h *Helm) Uninstall() error {
+	history, err := action.NewHistory(h.actionCfg).Run(h.chart.Name())
+	if errors.Is(err, driver.ErrReleaseNotFound) {
+		h.logger.Info("Helm release not found, skipping uninstall")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("inspecting release %q: %w", h.chart.Name(), err)
+	}
+	if owner := Owner(history); owner != "" && owner != h.instance {
+		return fmt.Errorf("%w: release %q in %q belongs to %q",
+			ErrReleaseOwnership, h.chart.Name(), h.namespace, owner)
+	}
+	if h.flags.DryRun {
+		h.logger.Info("Dry-run mode enabled, skipping uninstall")
+		return nil
+	}
+	c := action.NewUninstall(h.actionCfg)
+	c.Timeout = h.flags.Timeout
+	c.Wait = true
+	if _, err = c.Run(h.chart.Name()); err != nil {
+		return fmt.Errorf("uninstalling release %q: %w", h.chart.Name(), err)
+	}
+	h.logger.Info("Helm release uninstalled!")
+	return nil
+}
+
+// KeptResources returns the resources of the deployed release annotated with
+// the "keep" resource policy, which "helm uninstall" leaves behind. Empty when
+// the release is not found.
+func (h *Helm) KeptResources() ([]corev1.ObjectReference, error) {
+	rel, err := action.NewGet(h.actionCfg).Run(h.chart.Name())
+	if errors.Is(err, driver.ErrReleaseNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("inspecting release %q: %w", h.chart.Name(), err)
+	}
+	type object struct {
+		APIVersion string `yaml:"apiVersion"`
+		Kind       string `yaml:"kind"`
+		Metadata   struct {
+			Name        string            `yaml:"name"`
+			Namespace   string            `yaml:"namespace"`
+			Annotations map[string]string `yaml:"annotations"`
+		} `yaml:"metadata"`
+	}
+	kept := []corev1.ObjectReference{}
+	dec := yaml.NewDecoder(bytes.NewBufferString(rel.Manifest))
+	for {
+		var obj object
+		err := dec.Decode(&obj)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing release %q manifest: %w",
+				h.chart.Name(), err)
+		}
+		policy := obj.Metadata.Annotations[kube.ResourcePolicyAnno]
+		if obj.Kind == "" || policy != kube.KeepPolicy {
+			continue
+		}
+		namespace := obj.Metadata.Namespace
+		if namespace == "" {
+			namespace = h.namespace
+		}
+		kept = append(kept, corev1.ObjectReference{
+			APIVersion: obj.APIVersion,
+			Kind:       obj.Kind,
+			Name:       obj.Metadata.Name,
+			Namespace:  namespace,
+		})
+	}
+	return kept, nil
+}
+
+// GetNotes retrieves the latest release (version 0) of the Helm chart, printing
+// out the notes from the info section.
+func (h *Helm) GetNotes() (string, error) {
+	c := action.NewGet(h.actionCfg)
+	c.Version = 0
+
+	res, err := c.Run(h.chart.Name())
+	if err != nil {
+		return "", err
+	}
+	return res.Info.Notes, nil
+}
+
+// NewHelm creates a new Helm instance, setting up the Helm action configuration
+// to be used on subsequent interactions. The Helm instance is bound to a single
+// Helm Chart, the instance is the installation namespace recorded on the release.
+// The release information is printed on the informed writer.
+func NewHelm(
+	logger *slog.Logger,
+	out io.Writer,
+	f *flags.Flags,
+	kube k8s.Interface,
+	namespace string,
+	instance string,
+	chart *chart.Chart,
+) (*Helm, error) {
+	actionCfg := new(action.Configuration)
+	getter := kube.RESTClientGetter(namespace)
+	driver := os.Getenv("HELM_DRIVER")
+
+	loggerFn := func(format string, v ...interface{}) {
+		logger.WithGroup("helm-cli").Debug(fmt.Sprintf(format, v...))
+	}
+	err := actionCfg.Init(getter, namespace, driver, loggerFn)
+	if err != nil {
+		return nil, err
+	}
+
+	actionCfg.RegistryClient, err = registry.NewClient(
+		registry.ClientOptDebug(true))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Helm{
+		logger: logger.With(
+			"type", "helm",
+			"chart", chart.Name(),
+			"namespace", namespace,
+		),
+		out:       out,
+		flags:     f,
+		kube:      kube,
+		chart:     chart,
+		namespace: namespace,
+		instance:  instance,
+		actionCfg: actionCfg,
+	}, nil
+}
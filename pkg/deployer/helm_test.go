@@ -0,0 +1,165 @@
+package deployer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/annotations"
+	"github.com/redhat-appstudio/tssc-cli/pkg/flags"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	helmtime "helm.sh/helm/v3/pkg/time"
+)
+
+const (
+	testInstance = "tssc"
+	testTemplate = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: test
+data:
+  key: {{ .Values.key | quote }}
+`
+)
+
+// newTestChart creates a chart with a single ConfigMap template.
+func newTestChart(version string) *chart.Chart {
+	return &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV2,
+			Name:       "test",
+			Version:    version,
+		},
+		Templates: []*chart.File{{
+			Name: "templates/configmap.yaml",
+			Data: []byte(testTemplate),
+		}},
+	}
+}
+
+// newTestHelm creates the Helm client for the chart, with in-memory releases and
+// a fake Kubernetes client.
+func newTestHelm(t *testing.T, hc *chart.Chart) *Helm {
+	t.Helper()
+	return &Helm{
+		logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+		out:       io.Discard,
+		flags:     &flags.Flags{Timeout: time.Minute},
+		chart:     hc,
+		namespace: "test",
+		instance:  testInstance,
+		actionCfg: &action.Configuration{
+			Releases:     storage.Init(driver.NewMemory()),
+			KubeClient:   &kubefake.PrintingKubeClient{Out: io.Discard},
+			Capabilities: chartutil.DefaultCapabilities,
+			Log:          func(string, ...any) {},
+		},
+	}
+}
+
+// newTestRelease creates the release revision of the chart, rendered with the
+// informed values, on the status informed.
+func newTestRelease(
+	t *testing.T,
+	h *Helm,
+	version int,
+	vals chartutil.Values,
+	s release.Status,
+	lastDeployed time.Time,
+) *release.Release {
+	t.Helper()
+	rel, err := h.helmInstall(context.Background(), vals, true)
+	if err != nil {
+		t.Fatalf("rendering release: %v", err)
+	}
+	rel.Version = version
+	rel.Info.Status = s
+	rel.Info.LastDeployed = helmtime.Time{Time: lastDeployed}
+	rel.Labels = map[string]string{annotations.Instance: testInstance}
+	if err = h.actionCfg.Releases.Create(rel); err != nil {
+		t.Fatalf("storing release: %v", err)
+	}
+	return rel
+}
+
+func TestHelmDeployPending(t *testing.T) {
+	vals := chartutil.Values{"key": "value"}
+
+	tests := []struct {
+		name       string
+		age        time.Duration
+		anyPending bool
+		owner      string
+		err        error
+	}{{
+		name: "pending within the timeout",
+		age:  10 * time.Second,
+		err:  ErrOperationInProgress,
+	}, {
+		name: "pending for longer than the timeout",
+		age:  time.Hour,
+	}, {
+		name:       "pending within the timeout, recovery forced",
+		age:        10 * time.Second,
+		anyPending: true,
+	}, {
+		name:  "pending, owned by another installation",
+		age:   time.Hour,
+		owner: "other",
+		err:   ErrReleaseOwnership,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHelm(t, newTestChart("1.0.0"))
+			h.SetRecoverPending(tt.anyPending)
+			newTestRelease(t, h, 1, vals, release.StatusDeployed,
+				time.Now().Add(-2*time.Hour))
+			pending := newTestRelease(t, h, 2, vals, release.StatusPendingUpgrade,
+				time.Now().Add(-tt.age))
+			if tt.owner != "" {
+				pending.Labels[annotations.Instance] = tt.owner
+				if err := h.actionCfg.Releases.Update(pending); err != nil {
+					t.Fatalf("storing release: %v", err)
+				}
+			}
+
+			err := h.Deploy(context.Background(), vals)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected error %v, got %v", tt.err, err)
+				}
+				latest, err := h.actionCfg.Releases.Last("test")
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if latest.Version != 2 || !latest.Info.Status.IsPending() {
+					t.Errorf("expected the pending release untouched, got "+
+						"revision %d %s", latest.Version, latest.Info.Status)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			latest, err := h.actionCfg.Releases.Last("test")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if latest.Info.Status != release.StatusDeployed {
+				t.Errorf("expected the release deployed, got %s",
+					latest.Info.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package deployer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/annotations"
+	"github.com/redhat-appstudio/tssc-cli/pkg/status"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+// PlanAction represents what the deployment does with a Helm release.
+type PlanAction string
+
+const (
+	// PlanInstall the release doesn't exist, it's installed.
+	PlanInstall PlanAction = "install"
+	// PlanUpgrade the release differs from the chart and values, it's upgraded.
+	PlanUpgrade PlanAction = "upgrade"
+	// PlanNoop the release is up to date, it's skipped.
+	PlanNoop PlanAction = "no-op"
+	// PlanBlocked the release can't be changed, the deployment is refused.
+	PlanBlocked PlanAction = "blocked"
+)
+
+// ReleasePlan represents the action the deployment takes on a Helm release, and
+// the reason for it.
+type ReleasePlan struct {
+	Release   string     // helm release name
+	Namespace string     // release namespace
+	Revision  int        // deployed revision, zero when not installed
+	Action    PlanAction // deployment action
+	Reason    string     // reason for the action
+	Err       error      // error refusing the deployment, when blocked
+}
+
+// PrintReleasePlans prints the release plans to the writer formatted as a table.
+func PrintReleasePlans(w io.Writer, plans []*ReleasePlan) {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	row := func(a ...any) {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\n", a...)
+	}
+	row("Release", "Namespace", "Revision", "Action", "Reason")
+	for _, p := range plans {
+		revision := "-"
+		if p.Revision > 0 {
+			revision = fmt.Sprintf("%d", p.Revision)
+		}
+		row(p.Release, p.Namespace, revision, p.Action, p.Reason)
+	}
+	table.Flush()
+}
+
+// hooksManifest concatenates the manifests of the release hooks, e.g. the chart
+// tests, which are not part of the release manifest.
+func hooksManifest(rel *release.Release) string {
+	manifests := make([]string, 0, len(rel.Hooks))
+	for _, h := range rel.Hooks {
+		manifests = append(manifests, h.Manifest)
+	}
+	return strings.Join(manifests, "\n---\n")
+}
+
+// failedTests returns the names of the release test hooks which failed on their
+// last run.
+func failedTests(rel *release.Release) []string {
+	failed := []string{}
+	for _, h := range rel.Hooks {
+		if slices.Contains(h.Events, release.HookTest) &&
+			h.LastRun.Phase == release.HookPhaseFailed {
+			failed = append(failed, h.Name)
+		}
+	}
+	return failed
+}
+
+// chartVersion returns the chart version of the release, empty when unknown.
+func chartVersion(rel *release.Release) string {
+	if rel.Chart == nil || rel.Chart.Metadata == nil {
+		return ""
+	}
+	return rel.Chart.Metadata.Version
+}
+
+// Plan works out what Deploy would do with the release, without changing the
+// cluster. The release is installed when it doesn't exist, and blocked when
+// Deploy would refuse it: owned by another installation, or pending within the
+// timeout. It's skipped when the latest revision is deployed by this
+// installation with the same chart version, values digest, and manifests,
+// including hooks, as the chart rendered with the informed values, and its
+// tests didn't fail. Otherwise, the release is upgraded.
+func (h *Helm) Plan(
+	ctx context.Context,
+	vals chartutil.Values,
+) (*ReleasePlan, error) {
+	p := &ReleasePlan{Release: h.chart.Name(), Namespace: h.namespace}
+	deployed, err := action.NewGet(h.actionCfg).Run(h.chart.Name())
+	if errors.Is(err, driver.ErrReleaseNotFound) {
+		p.Action = PlanInstall
+		p.Reason = "release not found"
+		return p, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("inspecting release %q: %w", h.chart.Name(), err)
+	}
+	p.Revision = deployed.Version
+	if err = h.blocking(deployed); err != nil {
+		p.Action = PlanBlocked
+		p.Reason = err.Error()
+		p.Err = err
+		return p, nil
+	}
+	p.Action = PlanUpgrade
+
+	if deployed.Info == nil || deployed.Info.Status != release.StatusDeployed {
+		s := release.StatusUnknown
+		if deployed.Info != nil {
+			s = deployed.Info.Status
+		}
+		p.Reason = fmt.Sprintf("release status is %q", s)
+		return p, nil
+	}
+	if deployed.Labels[annotations.Instance] != h.instance {
+		p.Reason = "release instance label is missing"
+		return p, nil
+	}
+	if version := chartVersion(deployed); version != h.chart.Metadata.Version {
+		p.Reason = fmt.Sprintf("chart version %q, deployed %q",
+			h.chart.Metadata.Version, version)
+		return p, nil
+	}
+
+	deployedDigest, err := status.ValuesDigest(deployed.Config)
+	if err != nil {
+		return nil, err
+	}
+	digest, err := status.ValuesDigest(vals)
+	if err != nil {
+		return nil, err
+	}
+	if digest != deployedDigest {
+		p.Reason = "values changed"
+		return p, nil
+	}
+
+	rendered, err := h.helmUpgrade(ctx, vals, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range []struct {
+		name   string // manifest name
+		source string // deployed release manifest
+		target string // rendered chart manifest
+	}{
+		{"manifest", deployed.Manifest, rendered.Manifest},
+		{"hooks", hooksManifest(deployed), hooksManifest(rendered)},
+	} {
+		diff, err := NewManifestDiff(h.chart.Name(), h.namespace,
+			m.source, m.target, "release", "rendered chart")
+		if err != nil {
+			return nil, err
+		}
+		if diff.HasChanges() {
+			p.Reason = fmt.Sprintf("%s changed: %s", m.name, diff.Summary())
+			return p, nil
+		}
+	}
+
+	if failed := failedTests(deployed); len(failed) > 0 {
+		p.Reason = fmt.Sprintf("tests failed: %s", strings.Join(failed, ", "))
+		return p, nil
+	}
+
+	p.Action = PlanNoop
+	p.Reason = "release is up to date"
+	return p, nil
+}
@@ -0,0 +1,171 @@
+package deployer
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/annotations"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestHelmPlan(t *testing.T) {
+	deployed := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name   string
+		setup  func(*testing.T, *Helm) // deploys the release, when set
+		vals   chartutil.Values
+		action PlanAction
+		reason string
+	}{{
+		name:   "release not found",
+		vals:   chartutil.Values{"key": "value"},
+		action: PlanInstall,
+		reason: "release not found",
+	}, {
+		name: "release up to date",
+		setup: func(t *testing.T, h *Helm) {
+			newTestRelease(t, h, 1, chartutil.Values{"key": "value"},
+				release.StatusDeployed, deployed)
+		},
+		vals:   chartutil.Values{"key": "value"},
+		action: PlanNoop,
+		reason: "release is up to date",
+	}, {
+		name: "values changed",
+		setup: func(t *testing.T, h *Helm) {
+			newTestRelease(t, h, 1, chartutil.Values{"key": "value"},
+				release.StatusDeployed, deployed)
+		},
+		vals:   chartutil.Values{"key": "other"},
+		action: PlanUpgrade,
+		reason: "values changed",
+	}, {
+		name: "latest revision failed",
+		setup: func(t *testing.T, h *Helm) {
+			newTestRelease(t, h, 1, chartutil.Values{"key": "value"},
+				release.StatusFailed, deployed)
+		},
+		vals:   chartutil.Values{"key": "value"},
+		action: PlanUpgrade,
+		reason: `release status is "failed"`,
+	}, {
+		name: "deployed by another installation",
+		setup: func(t *testing.T, h *Helm) {
+			rel := newTestRelease(t, h, 1, chartutil.Values{"key": "value"},
+				release.StatusDeployed, deployed)
+			rel.Labels[annotations.Instance] = "other"
+			if err := h.actionCfg.Releases.Update(rel); err != nil {
+				t.Fatalf("storing release: %v", err)
+			}
+		},
+		vals:   chartutil.Values{"key": "value"},
+		action: PlanBlocked,
+		reason: ErrReleaseOwnership.Error(),
+	}, {
+		name: "deployed before the instance label existed",
+		setup: func(t *testing.T, h *Helm) {
+			rel := newTestRelease(t, h, 1, chartutil.Values{"key": "value"},
+				release.StatusDeployed, deployed)
+			delete(rel.Labels, annotations.Instance)
+			if err := h.actionCfg.Releases.Update(rel); err != nil {
+				t.Fatalf("storing release: %v", err)
+			}
+		},
+		vals:   chartutil.Values{"key": "value"},
+		action: PlanUpgrade,
+		reason: "release instance label is missing",
+	}, {
+		name: "pending within the timeout",
+		setup: func(t *testing.T, h *Helm) {
+			newTestRelease(t, h, 1, chartutil.Values{"key": "value"},
+				release.StatusPendingUpgrade, time.Now())
+		},
+		vals:   chartutil.Values{"key": "value"},
+		action: PlanBlocked,
+		reason: ErrOperationInProgress.Error(),
+	}, {
+		name: "pending for longer than the timeout",
+		setup: func(t *testing.T, h *Helm) {
+			newTestRelease(t, h, 1, chartutil.Values{"key": "value"},
+				release.StatusPendingUpgrade, deployed)
+		},
+		vals:   chartutil.Values{"key": "value"},
+		action: PlanUpgrade,
+		reason: `release status is "pending-upgrade"`,
+	}, {
+		name: "tests failed",
+		setup: func(t *testing.T, h *Helm) {
+			rel := newTestRelease(t, h, 1, chartutil.Values{"key": "value"},
+				release.StatusDeployed, deployed)
+			rel.Hooks = []*release.Hook{{
+				Name:    "test-connection",
+				Events:  []release.HookEvent{release.HookTest},
+				LastRun: release.HookExecution{Phase: release.HookPhaseFailed},
+			}}
+			if err := h.actionCfg.Releases.Update(rel); err != nil {
+				t.Fatalf("storing release: %v", err)
+			}
+		},
+		vals:   chartutil.Values{"key": "value"},
+		action: PlanUpgrade,
+		reason: "tests failed: test-connection",
+	}, {
+		name: "chart version changed",
+		setup: func(t *testing.T, h *Helm) {
+			current := h.chart
+			h.chart = newTestChart("0.9.0")
+			newTestRelease(t, h, 1, chartutil.Values{"key": "value"},
+				release.StatusDeployed, deployed)
+			h.chart = current
+		},
+		vals:   chartutil.Values{"key": "value"},
+		action: PlanUpgrade,
+		reason: `chart version "1.0.0", deployed "0.9.0"`,
+	}, {
+		name: "manifest changed with the same chart version",
+		setup: func(t *testing.T, h *Helm) {
+			current := h.chart
+			h.chart = newTestChart("1.0.0")
+			h.chart.Templates = append(h.chart.Templates, &chart.File{
+				Name: "templates/extra.yaml",
+				Data: []byte(strings.ReplaceAll(
+					testTemplate, "name: test", "name: extra")),
+			})
+			newTestRelease(t, h, 1, chartutil.Values{"key": "value"},
+				release.StatusDeployed, deployed)
+			h.chart = current
+		},
+		vals:   chartutil.Values{"key": "value"},
+		action: PlanUpgrade,
+		reason: "manifest changed",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHelm(t, newTestChart("1.0.0"))
+			if tt.setup != nil {
+				tt.setup(t, h)
+			}
+			p, err := h.Plan(context.Background(), tt.vals)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p.Action != tt.action {
+				t.Errorf("expected action %q, got %q (%s)",
+					tt.action, p.Action, p.Reason)
+			}
+			if !strings.HasPrefix(p.Reason, tt.reason) {
+				t.Errorf("expected reason %q, got %q", tt.reason, p.Reason)
+			}
+			if (p.Action == PlanBlocked) != (p.Err != nil) {
+				t.Errorf("expected an error only when blocked, got %v", p.Err)
+			}
+		})
+	}
+}
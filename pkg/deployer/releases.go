@@ -0,0 +1,145 @@
+package deployer
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/annotations"
+	"github.com/redhat-appstudio/tssc-cli/pkg/flags"
+	"github.com/redhat-appstudio/tssc-cli/pkg/k8s"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+// Releases represents the Helm releases management for the installer, it's
+// responsible for inspecting and removing releases already in the cluster.
+type Releases struct {
+	logger *slog.Logger // application logger
+	flags  *flags.Flags // global flags
+
+	namespace string                // kubernetes namespace, empty for all
+	instance  string                // installation namespace, empty for any
+	actionCfg *action.Configuration // helm action configuration
+}
+
+// Latest returns the latest revision of the release history, nil when empty.
+func Latest(history []*release.Release) *release.Release {
+	var latest *release.Release
+	for _, rel := range history {
+		if latest == nil || rel.Version > latest.Version {
+			latest = rel
+		}
+	}
+	return latest
+}
+
+// Owner returns the installation which owns the release, the instance label of
+// the latest revision. Empty for releases deployed before the label existed.
+func Owner(history []*release.Release) string {
+	latest := Latest(history)
+	if latest == nil {
+		return ""
+	}
+	return latest.Labels[annotations.Instance]
+}
+
+// List returns the deployed Helm releases, when the namespace is empty all
+// namespaces are inspected. Releases owned by other installations are skipped,
+// releases without owner are kept.
+func (r *Releases) List() ([]*release.Release, error) {
+	c := action.NewList(r.actionCfg)
+	c.AllNamespaces = r.namespace == ""
+	c.StateMask = action.ListDeployed | action.ListFailed |
+		action.ListPendingInstall | action.ListPendingUpgrade |
+		action.ListPendingRollback
+	rels, err := c.Run()
+	if err != nil || r.instance == "" {
+		return rels, err
+	}
+	owned := make([]*release.Release, 0, len(rels))
+	for _, rel := range rels {
+		owner := Owner([]*release.Release{rel})
+		if owner == "" || owner == r.instance {
+			owned = append(owned, rel)
+		}
+	}
+	return owned, nil
+}
+
+// Version returns the chart version of the latest release revision, empty when
+// the release is not found.
+func (r *Releases) Version(name string) (string, error) {
+	rel, err := action.NewGet(r.actionCfg).Run(name)
+	if errors.Is(err, driver.ErrReleaseNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("inspecting release %q: %w", name, err)
+	}
+	if rel.Chart == nil || rel.Chart.Metadata == nil {
+		return "", nil
+	}
+	return rel.Chart.Metadata.Version, nil
+}
+
+// Uninstall equivalent to "helm uninstall" command, it removes the release and
+// waits for its resources to be deleted. Releases owned by other installations
+// are not removed.
+func (r *Releases) Uninstall(name string) error {
+	if r.instance != "" {
+		history, err := action.NewHistory(r.actionCfg).Run(name)
+		if err != nil {
+			return fmt.Errorf("inspecting release %q: %w", name, err)
+		}
+		if owner := Owner(history); owner != "" && owner != r.instance {
+			return fmt.Errorf("%w: release %q in %q belongs to %q",
+				ErrReleaseOwnership, name, r.namespace, owner)
+		}
+	}
+	if r.flags.DryRun {
+		r.logger.Info("Dry-run mode enabled, skipping uninstall", "release", name)
+		return nil
+	}
+	c := action.NewUninstall(r.actionCfg)
+	c.Timeout = r.flags.Timeout
+	c.Wait = true
+	if _, err := c.Run(name); err != nil {
+		return fmt.Errorf("uninstalling release %q: %w", name, err)
+	}
+	r.logger.Info("Helm release uninstalled!", "release", name)
+	return nil
+}
+
+// NewReleases creates a new Releases instance, setting up the Helm action
+// configuration for the informed namespace. An empty namespace means all
+// namespaces, which is only suitable for listing releases. The instance is the
+// installation namespace owning the releases, empty for any.
+func NewReleases(
+	logger *slog.Logger,
+	f *flags.Flags,
+	kube k8s.Interface,
+	namespace string,
+	instance string,
+) (*Releases, error) {
+	actionCfg := new(action.Configuration)
+	getter := kube.RESTClientGetter(namespace)
+	driver := os.Getenv("HELM_DRIVER")
+
+	loggerFn := func(format string, v ...any) {
+		logger.WithGroup("helm-cli").Debug(fmt.Sprintf(format, v...))
+	}
+	if err := actionCfg.Init(getter, namespace, driver, loggerFn); err != nil {
+		return nil, err
+	}
+	return &Releases{
+		logger:    logger.With("type", "helm", "namespace", namespace),
+		flags:     f,
+		namespace: namespace,
+		instance:  instance,
+		actionCfg: actionCfg,
+	}, nil
+}
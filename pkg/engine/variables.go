@@ -0,0 +1,118 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/config"
+	"github.com/redhat-appstudio/tssc-cli/pkg/k8s"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// Variables represents the variables available for "values-template" file.
+type Variables struct {
+	Installer chartutil.Values // .Installer
+	OpenShift chartutil.Values // .OpenShift
+}
+
+// SetInstaller sets the installer configuration. The secret and environment
+// variable references on settings and product properties are resolved, so the
+// template receives the actual values.
+func (v *Variables) SetInstaller(
+	ctx context.Context,
+	kube k8s.Interface,
+	cfg *config.Config,
+) error {
+	refs := config.NewReferences(kube, cfg.Namespace())
+	v.Installer["Namespace"] = cfg.Namespace()
+	resolved, err := refs.Resolve(ctx, "settings", cfg.Installer.Settings)
+	if err != nil {
+		return err
+	}
+	settings, err := UnstructuredType(resolved)
+	if err != nil {
+		return err
+	}
+	v.Installer["Settings"] = settings.AsMap()
+	products := map[string]interface{}{}
+	for _, product := range cfg.Installer.Products {
+		if product.Properties != nil {
+			resolved, err = refs.Resolve(
+				ctx,
+				fmt.Sprintf("products.%s.properties", product.Name),
+				product.Properties,
+			)
+			if err != nil {
+				return err
+			}
+			product.Properties = resolved.(map[string]any)
+		}
+		products[product.KeyName()] = product
+	}
+	v.Installer["Products"], err = UnstructuredType(products)
+	return err
+}
+
+func getMinorVersion(version string) (string, error) {
+	parts := strings.Split(version, ".")
+	if len(parts) < 2 {
+		return "", fmt.Errorf("version does not include a minor part")
+	}
+	minorVersion := strings.Join(parts[:2], ".")
+
+	return minorVersion, nil
+}
+
+// SetOpenShift sets the OpenShift context variables.
+// On vanilla Kubernetes clusters, empty defaults are used.
+func (v *Variables) SetOpenShift(ctx context.Context, kube k8s.Interface) error {
+	// Try to get OpenShift-specific values, but don't fail if unavailable
+	ingressDomain, domainErr := k8s.GetOpenShiftIngressDomain(ctx, kube)
+	ingressRouterCA, caErr := k8s.GetOpenShiftIngressRouteCA(ctx, kube)
+	clusterVersion, versionErr := k8s.GetOpenShiftVersion(ctx, kube)
+
+	// If any OpenShift APIs are unavailable, use empty defaults
+	if domainErr != nil {
+		ingressDomain = ""
+	}
+	if caErr != nil {
+		ingressRouterCA = ""
+	}
+
+	minorVersion := ""
+	if versionErr == nil && clusterVersion != "" {
+		var err error
+		minorVersion, err = getMinorVersion(clusterVersion)
+		if err != nil {
+			minorVersion = ""
+		}
+	} else {
+		clusterVersion = ""
+	}
+
+	v.OpenShift = chartutil.Values{
+		"Ingress": chartutil.Values{
+			"Domain":   ingressDomain,
+			"RouterCA": ingressRouterCA,
+		},
+		"Version":      clusterVersion,
+		"MinorVersion": minorVersion,
+	}
+
+	return nil
+}
+
+// Unstructured returns the variables as "chartutils.Values".
+func (v *Variables) Unstructured() (chartutil.Values, error) {
+	return UnstructuredType(v)
+}
+
+// NewVariables instantiates Variables empty.
+func NewVariables() *Variables {
+	return &Variables{
+		Installer: chartutil.Values{},
+		OpenShift: chartutil.Values{},
+	}
+}
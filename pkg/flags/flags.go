@@ -0,0 +1,101 @@
+package flags
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Flags represents the global flags registered by the framework on the root
+// command, read back from the informed command once the command-line is parsed.
+type Flags struct {
+	Debug          bool          // debug mode
+	DryRun         bool          // dry-run mode
+	Instance       string        // installation namespace, empty for any
+	KubeConfigPath string        // path to the kubeconfig file
+	LogLevel       slog.Level    // log verbosity level
+	Timeout        time.Duration // helm client timeout
+}
+
+const (
+	// InstanceFlag flag name for the installation selector, registered by the
+	// installer on the root command.
+	InstanceFlag = "instance"
+	// DefaultLogLevel log level employed when the flag is not informed.
+	DefaultLogLevel = slog.LevelWarn
+	// DefaultTimeout helm client timeout employed when the flag is not informed.
+	DefaultTimeout = 15 * time.Minute
+)
+
+// GetLogger returns a logger instance for flag setting.
+func (f *Flags) GetLogger(out io.Writer) *slog.Logger {
+	logOpts := &slog.HandlerOptions{Level: f.LogLevel}
+	return slog.New(slog.NewTextHandler(out, logOpts))
+}
+
+// LoggerWith returns a logger with contextual information.
+func (f *Flags) LoggerWith(l *slog.Logger) *slog.Logger {
+	return l.With(
+		"debug", f.Debug,
+		"dry-run", f.DryRun,
+		"instance", f.Instance,
+		"timeout", f.Timeout,
+	)
+}
+
+// parseLogLevel converts the log level name into the typed "slog.Level".
+func parseLogLevel(level string) (slog.Level, error) {
+	switch level {
+	case "":
+		return DefaultLogLevel, nil
+	case "error":
+		return slog.LevelError, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "debug":
+		return slog.LevelDebug, nil
+	default:
+		return DefaultLogLevel, fmt.Errorf("unsupported log-level value %q", level)
+	}
+}
+
+// lookup returns the string value of the named flag, empty when the flag is not
+// registered on the command, or inherited from its parents.
+func lookup(cmd *cobra.Command, name string) string {
+	for _, p := range []*pflag.FlagSet{cmd.Flags(), cmd.InheritedFlags()} {
+		if f := p.Lookup(name); f != nil {
+			return f.Value.String()
+		}
+	}
+	return ""
+}
+
+// NewFlagsFromCommand instantiates the global flags using the values parsed for
+// the informed command, the root command persistent flags are inherited.
+func NewFlagsFromCommand(cmd *cobra.Command) (*Flags, error) {
+	f := &Flags{
+		Debug:          lookup(cmd, "debug") == "true",
+		DryRun:         lookup(cmd, "dry-run") == "true",
+		Instance:       lookup(cmd, InstanceFlag),
+		KubeConfigPath: lookup(cmd, "kube-config"),
+		Timeout:        DefaultTimeout,
+	}
+
+	var err error
+	if f.LogLevel, err = parseLogLevel(lookup(cmd, "log-level")); err != nil {
+		return nil, err
+	}
+	if timeout := lookup(cmd, "timeout"); timeout != "" {
+		if f.Timeout, err = time.ParseDuration(timeout); err != nil {
+			return nil, fmt.Errorf(
+				"unsupported duration value %q: %w", timeout, err)
+		}
+	}
+	return f, nil
+}
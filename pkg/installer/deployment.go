@@ -0,0 +1,684 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/annotations"
+	"github.com/redhat-appstudio/tssc-cli/pkg/config"
+	"github.com/redhat-appstudio/tssc-cli/pkg/deployer"
+	"github.com/redhat-appstudio/tssc-cli/pkg/flags"
+	"github.com/redhat-appstudio/tssc-cli/pkg/k8s"
+	"github.com/redhat-appstudio/tssc-cli/pkg/printer"
+	"github.com/redhat-appstudio/tssc-cli/pkg/resolver"
+	"github.com/redhat-appstudio/tssc-cli/pkg/status"
+
+	"golang.org/x/sync/errgroup"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ErrNamespaceOwnership when a product namespace belongs to another installation,
+// installations must not share namespaces.
+var ErrNamespaceOwnership = errors.New("namespace owned by another instance")
+
+// Deployment deploys the dependencies of a topology, organized in levels, using
+// an Installer per dependency. It checkpoints the progress in the cluster, and
+// alternatively compares or plans the releases without deploying.
+type Deployment struct {
+	logger     *slog.Logger   // application logger
+	flags      *flags.Flags   // global flags
+	kube       k8s.Interface  // kubernetes client
+	cfg        *config.Config // installer configuration
+	valuesTmpl string         // values template payload
+
+	statusManager     *status.Manager           // deployment state manager
+	recorded          *status.Status            // deployment state recorded
+	checkpointManager *status.CheckpointManager // deployment checkpoints manager
+	checkpoints       *status.Checkpoints       // deployment progress
+	checkpointsMu     sync.Mutex                // guards checkpoints updates
+	diffs             []*deployer.ManifestDiff  // manifest differences found
+	plans             []*deployer.ReleasePlan   // release plans, in order
+	diffsMu           sync.Mutex                // guards diffs updates
+	failures          map[string]error          // failed charts, by name
+	failuresMu        sync.Mutex                // guards failures updates
+
+	parallelism    int  // concurrent chart deployments
+	resume         bool // skips charts checkpointed with same inputs
+	diff           bool // compares the manifests, without deploying
+	diffLive       bool // compares with the live objects as well
+	plan           bool // shows the release plans, without deploying
+	forceUpgrade   bool // upgrades releases already up to date
+	recoverPending bool // recovers pending releases regardless of their age
+}
+
+// SetParallelism sets the maximum number of dependencies of the same level
+// deployed concurrently.
+func (d *Deployment) SetParallelism(parallelism int) {
+	d.parallelism = parallelism
+}
+
+// SetResume skips the dependencies checkpointed by the previous unfinished
+// deployment with identical inputs.
+func (d *Deployment) SetResume(resume bool) {
+	d.resume = resume
+}
+
+// SetDiff compares the rendered dependencies with their releases, and with the
+// live objects as well, instead of deploying.
+func (d *Deployment) SetDiff(diff, live bool) {
+	d.diff = diff
+	d.diffLive = live
+}
+
+// SetPlan shows the release plans instead of deploying.
+func (d *Deployment) SetPlan(plan bool) {
+	d.plan = plan
+}
+
+// SetForceUpgrade upgrades the releases already up to date.
+func (d *Deployment) SetForceUpgrade(force bool) {
+	d.forceUpgrade = force
+}
+
+// SetRecoverPending recovers the releases left pending regardless of their age,
+// otherwise only the releases pending for longer than the timeout.
+func (d *Deployment) SetRecoverPending(recoverPending bool) {
+	d.recoverPending = recoverPending
+}
+
+// Run deploys the dependencies level by level, a level starts once the previous
+// one is deployed. When the levels hold the whole topology, the deployment
+// starts over unless resuming, and the checkpoints are removed once complete.
+func (d *Deployment) Run(
+	ctx context.Context,
+	topology *resolver.Topology,
+	levels []resolver.Dependencies,
+) error {
+	total := 0
+	for _, level := range levels {
+		total += len(level)
+	}
+	partial := total < len(topology.Dependencies())
+
+	if err := d.checkDeployedVersions(topology, levels); err != nil {
+		return err
+	}
+	if err := d.checkNamespaces(ctx, levels); err != nil {
+		return err
+	}
+	if err := d.loadCheckpoints(ctx, partial); err != nil {
+		return err
+	}
+	recorded, err := d.statusManager.Get(ctx, d.cfg.Namespace())
+	if err != nil {
+		return err
+	}
+	d.recorded = recorded
+
+	if d.plan {
+		d.plans = make([]*deployer.ReleasePlan, total)
+	}
+	missing, err := d.missingNamespaces(ctx, levels)
+	if err != nil {
+		return err
+	}
+	index := 0
+	for _, level := range levels {
+		if err := d.deployLevel(ctx, level, index, total); err != nil {
+			return err
+		}
+		index += len(level)
+		if d.diff || d.plan {
+			continue
+		}
+		if missing, err = d.labelNamespaces(ctx, missing); err != nil {
+			return err
+		}
+		// Cleaning up temporary resources, once all dependencies of the level
+		// are deployed.
+		if err := k8s.RetryDeleteResources(
+			ctx, d.kube, d.cfg.Namespace(),
+		); err != nil {
+			d.logger.Debug(err.Error())
+		}
+	}
+
+	if d.diff {
+		d.printDiffSummary()
+		return nil
+	}
+	if d.plan {
+		fmt.Printf("\n%s\n# Deployment plan\n%s\n\n",
+			strings.Repeat("#", 60), strings.Repeat("#", 60))
+		deployer.PrintReleasePlans(os.Stdout, d.plans)
+		return nil
+	}
+	// The whole topology is deployed, the next deployment starts over.
+	if !partial && d.checkpoints != nil {
+		if err := d.checkpointManager.Delete(ctx, d.cfg.Namespace()); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Deployment complete!\n")
+	return nil
+}
+
+// Failures returns the errors of the charts which failed to deploy, by chart
+// name. The release left by the failure policy, e.g. rolled back, doesn't tell
+// the deployment failed.
+func (d *Deployment) Failures() map[string]error {
+	d.failuresMu.Lock()
+	defer d.failuresMu.Unlock()
+	return maps.Clone(d.failures)
+}
+
+// checkNamespaces asserts the dependencies namespaces don't belong to another
+// installation, either labelled by another instance, or holding the cluster
+// configuration of another installation.
+func (d *Deployment) checkNamespaces(
+	ctx context.Context,
+	levels []resolver.Dependencies,
+) error {
+	checked := []string{d.cfg.Namespace()}
+	for _, level := range levels {
+		for _, dep := range level {
+			name := dep.Namespace()
+			if slices.Contains(checked, name) {
+				continue
+			}
+			checked = append(checked, name)
+			ns, err := k8s.GetNamespace(ctx, d.kube, name)
+			if err != nil {
+				return err
+			}
+			if ns == nil {
+				continue
+			}
+			if owner := ns.GetLabels()[annotations.Instance]; owner != "" &&
+				owner != d.cfg.Namespace() {
+				return fmt.Errorf("%w: namespace %q belongs to instance %q",
+					ErrNamespaceOwnership, name, owner)
+			}
+			coreClient, err := d.kube.CoreV1ClientSet(name)
+			if err != nil {
+				return err
+			}
+			configMaps, err := coreClient.ConfigMaps(name).List(
+				ctx, metav1.ListOptions{LabelSelector: config.Selector})
+			if err != nil {
+				return err
+			}
+			if len(configMaps.Items) > 0 {
+				return fmt.Errorf(
+					"%w: namespace %q holds the configuration of instance %q",
+					ErrNamespaceOwnership, name, name)
+			}
+		}
+	}
+	return nil
+}
+
+// missingNamespaces returns the namespaces of the dependencies which don't exist
+// yet, thus created by the deployment. The installer namespace is not included.
+// Nothing is created on dry-run, diff or plan.
+func (d *Deployment) missingNamespaces(
+	ctx context.Context,
+	levels []resolver.Dependencies,
+) ([]string, error) {
+	missing := []string{}
+	if d.flags.DryRun || d.diff || d.plan {
+		return missing, nil
+	}
+	for _, level := range levels {
+		for _, dep := range level {
+			name := dep.Namespace()
+			if name == d.cfg.Namespace() || slices.Contains(missing, name) {
+				continue
+			}
+			ns, err := k8s.GetNamespace(ctx, d.kube, name)
+			if err != nil {
+				return nil, err
+			}
+			if ns == nil {
+				missing = append(missing, name)
+			}
+		}
+	}
+	return missing, nil
+}
+
+// labelNamespaces labels the informed namespaces which exist by now with the
+// owning installation, so uninstall only deletes the namespaces the deployment
+// created. Returns the namespaces still missing.
+func (d *Deployment) labelNamespaces(
+	ctx context.Context,
+	namespaces []string,
+) ([]string, error) {
+	missing := []string{}
+	for _, name := range namespaces {
+		ns, err := k8s.GetNamespace(ctx, d.kube, name)
+		if err != nil {
+			return nil, err
+		}
+		if ns == nil {
+			missing = append(missing, name)
+			continue
+		}
+		d.logger.Debug("Labelling the namespace created", "namespace", name)
+		if err = k8s.LabelNamespace(ctx, d.kube, name, map[string]string{
+			annotations.Instance: d.cfg.Namespace(),
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return missing, nil
+}
+
+// loadCheckpoints loads the deployment checkpoints, when resuming, or when only
+// part of the topology is deployed. Otherwise, a new deployment starts with
+// empty checkpoints. Nothing is checkpointed on dry-run.
+func (d *Deployment) loadCheckpoints(ctx context.Context, partial bool) error {
+	if d.flags.DryRun {
+		return nil
+	}
+	var err error
+	if !d.resume && !partial {
+		d.checkpoints, err = d.checkpointManager.Update(
+			ctx,
+			d.cfg.Namespace(),
+			func(c *status.Checkpoints) error {
+				c.Started = time.Now().UTC()
+				c.Charts = map[string]status.Checkpoint{}
+				return nil
+			},
+		)
+		return err
+	}
+	d.checkpoints, err = d.checkpointManager.Get(ctx, d.cfg.Namespace())
+	if err != nil {
+		return err
+	}
+	if d.resume {
+		fmt.Printf("Resuming the deployment started at %s, %d chart(s) "+
+			"checkpointed.\n", d.checkpoints.Started.Format(time.RFC3339),
+			len(d.checkpoints.Charts))
+	}
+	return nil
+}
+
+// checkpoint returns the checkpoint of the dependency, with the digests of the
+// chart and the informed rendered values.
+func (d *Deployment) checkpoint(
+	dep *resolver.Dependency,
+	values map[string]any,
+) (status.Checkpoint, error) {
+	cp := status.Checkpoint{Namespace: dep.Namespace()}
+	var err error
+	if cp.ChartDigest, err = status.ChartDigest(dep.Chart()); err != nil {
+		return cp, err
+	}
+	cp.ValuesDigest, err = status.ValuesDigest(values)
+	return cp, err
+}
+
+// checkpointed checks whether resuming, and the dependency has been deployed with
+// identical inputs, returning when.
+func (d *Deployment) checkpointed(
+	name string,
+	cp status.Checkpoint,
+) (time.Time, bool) {
+	if !d.resume {
+		return time.Time{}, false
+	}
+	d.checkpointsMu.Lock()
+	defer d.checkpointsMu.Unlock()
+	if !d.checkpoints.Done(name, cp) {
+		return time.Time{}, false
+	}
+	return d.checkpoints.Charts[name].Completed, true
+}
+
+// recordCheckpoint records the dependency as deployed, storing the checkpoints
+// in the cluster. Concurrent deployments record one at a time.
+func (d *Deployment) recordCheckpoint(
+	ctx context.Context,
+	name string,
+	cp status.Checkpoint,
+) error {
+	d.checkpointsMu.Lock()
+	defer d.checkpointsMu.Unlock()
+	checkpoints, err := d.checkpointManager.Update(
+		ctx,
+		d.cfg.Namespace(),
+		func(c *status.Checkpoints) error {
+			c.Record(name, cp)
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+	d.checkpoints = checkpoints
+	return nil
+}
+
+// checkDeployedVersions asserts the releases already deployed in the cluster, of
+// the charts required by the dependencies to deploy, satisfy the "depends-on"
+// version constraints. The charts deployed on this run are asserted by the
+// collection instead.
+func (d *Deployment) checkDeployedVersions(
+	topology *resolver.Topology,
+	levels []resolver.Dependencies,
+) error {
+	deploying := map[string]bool{}
+	for _, level := range levels {
+		for _, dep := range level {
+			deploying[dep.Name()] = true
+		}
+	}
+	for _, level := range levels {
+		for _, dep := range level {
+			constraints, err := dep.DependsOnConstraints()
+			if err != nil {
+				return err
+			}
+			for _, dc := range constraints {
+				if dc.Constraint == nil || deploying[dc.Name] {
+					continue
+				}
+				required, err := topology.GetDependency(dc.Name)
+				if err != nil {
+					continue
+				}
+				releases, err := deployer.NewReleases(
+					d.logger,
+					d.flags,
+					d.kube,
+					required.Namespace(),
+					d.cfg.Namespace(),
+				)
+				if err != nil {
+					return err
+				}
+				version, err := releases.Version(dc.Name)
+				if err != nil {
+					return err
+				}
+				if version == "" {
+					continue
+				}
+				if err = dc.Check(version); err != nil {
+					return fmt.Errorf("chart %q, deployed release in %q: %w",
+						dep.Name(), required.Namespace(), err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// failurePolicy returns the failure policy of the informed dependency, the
+// chart annotation takes precedence over the "failurePolicy" setting.
+func (d *Deployment) failurePolicy(dep *resolver.Dependency) (string, error) {
+	policy := dep.FailurePolicy()
+	if policy == "" {
+		if setting, ok := d.cfg.Installer.Settings["failurePolicy"].(string); ok {
+			policy = setting
+		}
+	}
+	if policy == "" {
+		return deployer.FailurePolicyLeave, nil
+	}
+	if err := deployer.ValidateFailurePolicy(policy); err != nil {
+		return "", fmt.Errorf("dependency %q: %w", dep.Name(), err)
+	}
+	return policy, nil
+}
+
+// diffDependency compares the rendered dependency with its release, printing
+// the differences on the informed writer.
+func (d *Deployment) diffDependency(
+	ctx context.Context,
+	out io.Writer,
+	i *Installer,
+) error {
+	diffs, err := i.Diff(ctx, d.diffLive)
+	if err != nil {
+		return err
+	}
+	for _, diff := range diffs {
+		diff.PrintText(out)
+	}
+	d.diffsMu.Lock()
+	defer d.diffsMu.Unlock()
+	d.diffs = append(d.diffs, diffs...)
+	return nil
+}
+
+// printDiffSummary prints the number of resources added, changed and removed per
+// chart, for all the comparisons made.
+func (d *Deployment) printDiffSummary() {
+	fmt.Printf("\n%s\n# Diff summary\n%s\n\n",
+		strings.Repeat("#", 60), strings.Repeat("#", 60))
+	changed := 0
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(table, "Release\tNamespace\tCompared\tAdded\tChanged\tRemoved\n")
+	for _, diff := range d.diffs {
+		if diff.HasChanges() {
+			changed++
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%d\t%d\t%d\n",
+			diff.Release, diff.Namespace, diff.From,
+			diff.Count(deployer.ResourceAdded),
+			diff.Count(deployer.ResourceChanged),
+			diff.Count(deployer.ResourceRemoved))
+	}
+	table.Flush()
+	fmt.Printf("\n%d of %d comparison(s) with changes, nothing deployed.\n",
+		changed, len(d.diffs))
+}
+
+// planDependency works out the release plan of the dependency. An up to date
+// release is upgraded when forced, or when its last deployment is recorded as
+// failed. The plan is printed on the informed writer.
+func (d *Deployment) planDependency(
+	ctx context.Context,
+	out io.Writer,
+	i *Installer,
+) (*deployer.ReleasePlan, error) {
+	p, err := i.Plan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if c, ok := d.recorded.Charts[p.Release]; ok &&
+		c.Outcome == status.OutcomeFailed && p.Action == deployer.PlanNoop {
+		p.Action = deployer.PlanUpgrade
+		p.Reason = fmt.Sprintf("last deployment failed: %s", c.Error)
+	}
+	if d.forceUpgrade && p.Action == deployer.PlanNoop {
+		p.Action = deployer.PlanUpgrade
+		p.Reason = fmt.Sprintf("forced, %s", p.Reason)
+	}
+	fmt.Fprintf(out, "# Plan: %s, %s.\n", p.Action, p.Reason)
+	return p, nil
+}
+
+// deployDependency renders the values and deploys a single dependency, the
+// output and logs are written on the informed writer.
+func (d *Deployment) deployDependency(
+	ctx context.Context,
+	out io.Writer,
+	dep resolver.Dependency,
+	index int,
+	total int,
+) error {
+	action := "Deploying"
+	if d.diff {
+		action = "Comparing"
+	} else if d.plan {
+		action = "Planning"
+	}
+	fmt.Fprintf(out, "\n\n%s\n", strings.Repeat("#", 60))
+	fmt.Fprintf(
+		out,
+		"# [%d/%d] %s '%s' in '%s'.\n",
+		index,
+		total,
+		action,
+		dep.Name(),
+		dep.Namespace(),
+	)
+	fmt.Fprintf(out, "%s\n", strings.Repeat("#", 60))
+
+	logger := d.flags.LoggerWith(d.flags.GetLogger(out))
+	i := NewInstaller(logger, out, d.flags, d.kube, &dep)
+	policy, err := d.failurePolicy(&dep)
+	if err != nil {
+		return err
+	}
+	i.SetFailurePolicy(policy)
+	i.SetRecoverPending(d.recoverPending)
+	if err := i.SetValues(ctx, d.cfg, d.valuesTmpl); err != nil {
+		return err
+	}
+	if d.flags.Debug {
+		i.PrintRawValues()
+	}
+	if err := i.RenderValues(); err != nil {
+		return err
+	}
+	if d.flags.Debug {
+		i.PrintValues()
+	}
+	if d.diff {
+		if err := d.diffDependency(ctx, out, i); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%s\n", strings.Repeat("#", 60))
+		return nil
+	}
+	if d.plan {
+		p, err := d.planDependency(ctx, out, i)
+		if err != nil {
+			return err
+		}
+		d.plans[index-1] = p
+		fmt.Fprintf(out, "%s\n", strings.Repeat("#", 60))
+		return nil
+	}
+
+	var cp status.Checkpoint
+	if d.checkpoints != nil {
+		if cp, err = d.checkpoint(&dep, i.Values()); err != nil {
+			return err
+		}
+		if completed, done := d.checkpointed(dep.Name(), cp); done {
+			fmt.Fprintf(out, "# Skipping, deployed with identical chart and "+
+				"values at %s.\n", completed.Format(time.RFC3339))
+			fmt.Fprintf(out, "%s\n", strings.Repeat("#", 60))
+			return nil
+		}
+	}
+	p, err := d.planDependency(ctx, out, i)
+	if err != nil {
+		return err
+	}
+	if p.Action == deployer.PlanBlocked {
+		return p.Err
+	}
+	if p.Action != deployer.PlanNoop {
+		if err = i.Install(ctx); err != nil {
+			d.failuresMu.Lock()
+			d.failures[dep.Name()] = err
+			d.failuresMu.Unlock()
+			return err
+		}
+	}
+	if d.checkpoints != nil {
+		if err = d.recordCheckpoint(ctx, dep.Name(), cp); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(out, "%s\n", strings.Repeat("#", 60))
+	return nil
+}
+
+// deployLevel deploys the dependencies of a level, concurrently up to the
+// parallelism limit, the output lines of each dependency are prefixed with its
+// name. After a failure no further dependency of the level is started. The
+// offset is the number of dependencies deployed before the level.
+func (d *Deployment) deployLevel(
+	ctx context.Context,
+	level resolver.Dependencies,
+	offset int,
+	total int,
+) error {
+	if len(level) == 1 {
+		return d.deployDependency(ctx, os.Stdout, level[0], offset+1, total)
+	}
+
+	names := make([]string, 0, len(level))
+	for _, dep := range level {
+		names = append(names, dep.Name())
+	}
+	fmt.Printf("\n# Deploying concurrently: %s\n", strings.Join(names, ", "))
+
+	stdout := printer.NewSyncWriter(os.Stdout)
+	// Once a dependency fails the group context is canceled, the dependencies
+	// waiting for a slot are not started. The ones already running keep the
+	// parent context, interrupting a Helm operation would leave it pending.
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(d.parallelism)
+	for i, dep := range level {
+		g.Go(func() error {
+			if err := gctx.Err(); err != nil {
+				fmt.Fprintf(stdout, "# Skipping %q: %v\n", dep.Name(), err)
+				return nil
+			}
+			out := printer.NewPrefixWriter(
+				stdout, fmt.Sprintf("[%s] ", dep.Name()))
+			defer out.Flush()
+			if err := d.deployDependency(
+				ctx, out, dep, offset+i+1, total,
+			); err != nil {
+				return fmt.Errorf("deploying %q: %w", dep.Name(), err)
+			}
+			return nil
+		})
+	}
+	return g.Wait()
+}
+
+// NewDeployment instantiates the Deployment of the installer configuration, the
+// values template is rendered for each dependency. By default, dependencies
+// are deployed one at a time.
+func NewDeployment(
+	logger *slog.Logger,
+	f *flags.Flags,
+	kube k8s.Interface,
+	appName string,
+	cfg *config.Config,
+	valuesTmpl string,
+) *Deployment {
+	return &Deployment{
+		logger:            logger.With("type", "deployment"),
+		flags:             f,
+		kube:              kube,
+		cfg:               cfg,
+		valuesTmpl:        valuesTmpl,
+		statusManager:     status.NewManager(kube, appName),
+		checkpointManager: status.NewCheckpointManager(kube, appName),
+		failures:          map[string]error{},
+		parallelism:       1,
+	}
+}
@@ -0,0 +1,232 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/config"
+	"github.com/redhat-appstudio/tssc-cli/pkg/deployer"
+	"github.com/redhat-appstudio/tssc-cli/pkg/engine"
+	"github.com/redhat-appstudio/tssc-cli/pkg/flags"
+	"github.com/redhat-appstudio/tssc-cli/pkg/k8s"
+	"github.com/redhat-appstudio/tssc-cli/pkg/monitor"
+	"github.com/redhat-appstudio/tssc-cli/pkg/printer"
+	"github.com/redhat-appstudio/tssc-cli/pkg/resolver"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// Installer represents the "helm install" using its APIs, this component deploys
+// the informed dependency on the pre-configured namespace.
+type Installer struct {
+	logger *slog.Logger         // application logger
+	out    io.Writer            // installer output
+	flags  *flags.Flags         // global flags
+	kube   k8s.Interface        // kubernetes client
+	dep    *resolver.Dependency // dependency to install
+
+	instance       string           // installation namespace
+	valuesBytes    []byte           // rendered values
+	values         chartutil.Values // helm chart values
+	failurePolicy  string           // action taken on failed releases
+	recoverPending bool             // recovers pending releases regardless
+}
+
+// SetFailurePolicy sets the action taken when the release fails to deploy or
+// verify, see deployer.FailurePolicies.
+func (i *Installer) SetFailurePolicy(policy string) {
+	i.failurePolicy = policy
+}
+
+// SetRecoverPending recovers the release left pending regardless of how long
+// ago it was changed, see deployer.Helm.
+func (i *Installer) SetRecoverPending(recoverPending bool) {
+	i.recoverPending = recoverPending
+}
+
+// recover applies the failure policy on the release, the original error is
+// returned along with the recovery error, when any.
+func (i *Installer) recover(hc *deployer.Helm, err error) error {
+	i.logger.Debug("Applying the failure policy", "policy", i.failurePolicy)
+	if recoverErr := hc.Recover(i.failurePolicy); recoverErr != nil {
+		return fmt.Errorf("%w (failure policy %q: %w)",
+			err, i.failurePolicy, recoverErr)
+	}
+	return err
+}
+
+// SetValues prepares the values template for the Helm chart installation.
+func (i *Installer) SetValues(
+	ctx context.Context,
+	cfg *config.Config,
+	valuesTmpl string,
+) error {
+	i.logger.Debug("Preparing values template context")
+	i.instance = cfg.Namespace()
+	variables := engine.NewVariables()
+	err := variables.SetInstaller(ctx, i.kube, cfg)
+	if err != nil {
+		return err
+	}
+	if err = variables.SetOpenShift(ctx, i.kube); err != nil {
+		return err
+	}
+
+	i.logger.Debug("Rendering values template")
+	i.valuesBytes, err = engine.NewEngine(i.kube, valuesTmpl).Render(variables)
+	return err
+}
+
+// PrintRawValues prints the raw values template to the console.
+func (i *Installer) PrintRawValues() {
+	i.logger.Debug("Showing raw results of rendered values template")
+	fmt.Fprintf(i.out, "#\n# Values (Raw)\n#\n\n%s\n", i.valuesBytes)
+}
+
+// RenderValues parses the values template and prepares the Helm chart values.
+func (i *Installer) RenderValues() error {
+	if i.valuesBytes == nil {
+		return fmt.Errorf("values not set")
+	}
+
+	i.logger.Debug("Preparing rendered values for Helm installation")
+	var err error
+	i.values, err = chartutil.ReadValues(i.valuesBytes)
+	return err
+}
+
+// Values exposes the Helm chart values, rendered by RenderValues.
+func (i *Installer) Values() chartutil.Values {
+	return i.values
+}
+
+// PrintValues prints the parsed values to the console.
+func (i *Installer) PrintValues() {
+	i.logger.Debug("Showing parsed values")
+	printer.ValuesPrinter(i.out, "Values", i.values)
+}
+
+// Install performs the installation of the Helm chart.
+func (i *Installer) Install(ctx context.Context) error {
+	if i.values == nil {
+		return fmt.Errorf("values not set")
+	}
+
+	i.logger.Debug("Loading Helm client for dependency and namespace")
+	hc, err := deployer.NewHelm(
+		i.logger,
+		i.out,
+		i.flags,
+		i.kube,
+		i.dep.Namespace(),
+		i.instance,
+		i.dep.Chart(),
+	)
+	if err != nil {
+		return err
+	}
+	hc.SetRecoverPending(i.recoverPending)
+
+	// Performing the installation, or upgrade, of the Helm chart dependency,
+	// using the values rendered before hand.
+	i.logger.Debug("Installing the Helm chart")
+	if err = hc.Deploy(ctx, i.values); err != nil {
+		return i.recover(hc, err)
+	}
+	// Verifying if the installation was successful, by running the Helm chart
+	// tests interactively.
+	i.logger.Debug("Verifying the Helm chart release")
+	if err = hc.VerifyWithRetry(); err != nil {
+		return i.recover(hc, err)
+	}
+
+	if !i.flags.DryRun {
+		m := monitor.NewMonitor(i.logger, i.kube)
+		i.logger.Debug("Collecting resources for monitoring...")
+		if err = hc.VisitReleaseResources(ctx, m); err != nil {
+			return err
+		}
+		i.logger.Debug("Monitoring the Helm chart release...")
+		if err = m.Watch(i.flags.Timeout); err != nil {
+			return i.recover(hc, err)
+		}
+		i.logger.Debug("Monitoring completed, release is successful!")
+	} else {
+		i.logger.Debug("Skipping monitoring (dry-run)")
+	}
+
+	i.logger.Info("Helm chart installed!")
+	return nil
+}
+
+// Plan works out whether the Helm chart release is installed, upgraded, or left
+// as it is because it's up to date, without changing the cluster.
+func (i *Installer) Plan(ctx context.Context) (*deployer.ReleasePlan, error) {
+	if i.values == nil {
+		return nil, fmt.Errorf("values not set")
+	}
+
+	i.logger.Debug("Loading Helm client for dependency and namespace")
+	hc, err := deployer.NewHelm(
+		i.logger,
+		i.out,
+		i.flags,
+		i.kube,
+		i.dep.Namespace(),
+		i.instance,
+		i.dep.Chart(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	i.logger.Debug("Planning the Helm chart release")
+	return hc.Plan(ctx, i.values)
+}
+
+// Diff compares the Helm chart rendered with the values against the deployed
+// release, and the live objects when informed, without changing the cluster.
+func (i *Installer) Diff(
+	ctx context.Context,
+	live bool,
+) ([]*deployer.ManifestDiff, error) {
+	if i.values == nil {
+		return nil, fmt.Errorf("values not set")
+	}
+
+	i.logger.Debug("Loading Helm client for dependency and namespace")
+	hc, err := deployer.NewHelm(
+		i.logger,
+		i.out,
+		i.flags,
+		i.kube,
+		i.dep.Namespace(),
+		i.instance,
+		i.dep.Chart(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	i.logger.Debug("Comparing the Helm chart with the release")
+	return hc.Diff(ctx, i.values, live)
+}
+
+// NewInstaller instantiates a new installer for the given dependency, the values
+// and release information are printed on the informed writer.
+func NewInstaller(
+	logger *slog.Logger,
+	out io.Writer,
+	f *flags.Flags,
+	kube k8s.Interface,
+	dep *resolver.Dependency,
+) *Installer {
+	return &Installer{
+		logger:        dep.LoggerWith(logger),
+		out:           out,
+		flags:         f,
+		kube:          kube,
+		dep:           dep,
+		failurePolicy: deployer.FailurePolicyLeave,
+	}
+}
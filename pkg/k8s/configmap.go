@@ -0,0 +1,70 @@
+package k8s
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/retry"
+)
+
+// UpdateConfigMap applies the mutation on the data of the named ConfigMap, read,
+// modified and written conditioned to the resource version read. On conflict,
+// the ConfigMap is read and the mutation applied again. The ConfigMap is created
+// with the informed labels when it doesn't exist yet. The mutation returns
+// whether the data changed, nothing is written otherwise.
+func UpdateConfigMap(
+	ctx context.Context,
+	kube Interface,
+	namespace string,
+	name string,
+	labels map[string]string,
+	mutate func(data map[string]string) (bool, error),
+) error {
+	coreClient, err := kube.CoreV1ClientSet(namespace)
+	if err != nil {
+		return err
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := coreClient.ConfigMaps(namespace).
+			Get(ctx, name, metav1.GetOptions{})
+		notFound := apierrors.IsNotFound(err)
+		if err != nil && !notFound {
+			return err
+		}
+		if notFound {
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			}
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		changed, err := mutate(cm.Data)
+		if err != nil || !changed {
+			return err
+		}
+		if cm.Labels == nil {
+			cm.Labels = map[string]string{}
+		}
+		for k, v := range labels {
+			cm.Labels[k] = v
+		}
+
+		if !notFound {
+			_, err = coreClient.ConfigMaps(namespace).
+				Update(ctx, cm, metav1.UpdateOptions{})
+			return err
+		}
+		_, err = coreClient.ConfigMaps(namespace).
+			Create(ctx, cm, metav1.CreateOptions{})
+		if apierrors.IsAlreadyExists(err) {
+			// Created concurrently, the mutation is applied on the latest.
+			return apierrors.NewConflict(
+				schema.GroupResource{Resource: "configmaps"}, name, err)
+		}
+		return err
+	})
+}
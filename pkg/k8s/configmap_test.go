@@ -0,0 +1,129 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// fakeKube serves a single fake clientset, only the CoreV1 client is used.
+type fakeKube struct {
+	Interface
+	cs *fake.Clientset
+}
+
+func (f *fakeKube) CoreV1ClientSet(string) (corev1client.CoreV1Interface, error) {
+	return f.cs.CoreV1(), nil
+}
+
+// appendKey mutation appends the key to the "keys" entry.
+func appendKey(key string) func(map[string]string) (bool, error) {
+	return func(data map[string]string) (bool, error) {
+		data["keys"] += key
+		return true, nil
+	}
+}
+
+func TestUpdateConfigMap(t *testing.T) {
+	ctx := context.Background()
+	labels := map[string]string{"app": "test"}
+
+	tests := []struct {
+		name     string
+		objects  []runtime.Object
+		conflict bool
+		expected string
+	}{{
+		name:     "creates missing configmap",
+		expected: "a",
+	}, {
+		name: "updates existing configmap",
+		objects: []runtime.Object{&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "ns"},
+			Data:       map[string]string{"keys": "x"},
+		}},
+		expected: "xa",
+	}, {
+		name: "applies the mutation again on conflict",
+		objects: []runtime.Object{&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "ns"},
+			Data:       map[string]string{"keys": "x"},
+		}},
+		conflict: true,
+		expected: "xya",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := fake.NewClientset(tt.objects...)
+			if tt.conflict {
+				conflicted := false
+				cs.PrependReactor("update", "configmaps", func(
+					action k8stesting.Action,
+				) (bool, runtime.Object, error) {
+					if conflicted {
+						return false, nil, nil
+					}
+					conflicted = true
+					// A concurrent change lands before the update.
+					cm, err := cs.Tracker().Get(
+						schema.GroupVersionResource{Version: "v1", Resource: "configmaps"},
+						"ns", "cm")
+					if err != nil {
+						return true, nil, err
+					}
+					concurrent := cm.(*corev1.ConfigMap).DeepCopy()
+					concurrent.Data["keys"] += "y"
+					if err = cs.Tracker().Update(
+						schema.GroupVersionResource{Version: "v1", Resource: "configmaps"},
+						concurrent, "ns"); err != nil {
+						return true, nil, err
+					}
+					return true, nil, apierrors.NewConflict(
+						schema.GroupResource{Resource: "configmaps"}, "cm", nil)
+				})
+			}
+
+			kube := &fakeKube{cs: cs}
+			if err := UpdateConfigMap(
+				ctx, kube, "ns", "cm", labels, appendKey("a"),
+			); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			cm, err := cs.CoreV1().ConfigMaps("ns").
+				Get(ctx, "cm", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cm.Data["keys"] != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, cm.Data["keys"])
+			}
+			if cm.Labels["app"] != "test" {
+				t.Errorf("expected labels %v, got %v", labels, cm.Labels)
+			}
+		})
+	}
+
+	t.Run("unchanged data is not written", func(t *testing.T) {
+		cs := fake.NewClientset()
+		kube := &fakeKube{cs: cs}
+		if err := UpdateConfigMap(ctx, kube, "ns", "cm", labels,
+			func(map[string]string) (bool, error) { return false, nil },
+		); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, err := cs.CoreV1().ConfigMaps("ns").
+			Get(ctx, "cm", metav1.GetOptions{})
+		if !apierrors.IsNotFound(err) {
+			t.Fatalf("expected not found, got %v", err)
+		}
+	})
+}
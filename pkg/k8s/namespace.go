@@ -0,0 +1,94 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+)
+
+// DeleteNamespace deletes a Kubernetes namespace and waits, up to the timeout,
+// until it's gone, thus the finalizers of its resources have run. A missing
+// namespace is not an error.
+func DeleteNamespace(
+	ctx context.Context,
+	kube Interface,
+	name string,
+	timeout time.Duration,
+) error {
+	coreClient, err := kube.CoreV1ClientSet(name)
+	if err != nil {
+		return err
+	}
+	err = coreClient.Namespaces().Delete(ctx, name, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	err = wait.PollUntilContextTimeout(
+		ctx, 5*time.Second, timeout, true,
+		func(ctx context.Context) (bool, error) {
+			_, err := coreClient.Namespaces().Get(ctx, name, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				return true, nil
+			}
+			return false, err
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("waiting for namespace %q deletion: %w", name, err)
+	}
+	return nil
+}
+
+// GetNamespace retrieves the namespace, nil when it doesn't exist.
+func GetNamespace(
+	ctx context.Context,
+	kube Interface,
+	name string,
+) (*corev1.Namespace, error) {
+	coreClient, err := kube.CoreV1ClientSet(name)
+	if err != nil {
+		return nil, err
+	}
+	ns, err := coreClient.Namespaces().Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return ns, err
+}
+
+// LabelNamespace adds the labels on the existing namespace, read and updated
+// again on conflict.
+func LabelNamespace(
+	ctx context.Context,
+	kube Interface,
+	name string,
+	labels map[string]string,
+) error {
+	coreClient, err := kube.CoreV1ClientSet(name)
+	if err != nil {
+		return err
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		ns, err := coreClient.Namespaces().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if ns.Labels == nil {
+			ns.Labels = map[string]string{}
+		}
+		for k, v := range labels {
+			ns.Labels[k] = v
+		}
+		_, err = coreClient.Namespaces().Update(ctx, ns, metav1.UpdateOptions{})
+		return err
+	})
+}
@@ -0,0 +1,93 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// DeleteObject deletes the Kubernetes object referenced, a missing object is not
+// an error.
+func DeleteObject(
+	ctx context.Context,
+	kube Interface,
+	ref *corev1.ObjectReference,
+) error {
+	client, err := kube.GetDynamicClientForObjectRef(ref)
+	if err != nil {
+		return err
+	}
+	err = client.Delete(ctx, ref.Name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("deleting %s %s/%s: %w",
+			ref.Kind, ref.Namespace, ref.Name, err)
+	}
+	return nil
+}
+
+// GetObject retrieves the Kubernetes object referenced, nil is returned when the
+// object, or its API, doesn't exist.
+func GetObject(
+	ctx context.Context,
+	kube Interface,
+	ref *corev1.ObjectReference,
+) (*unstructured.Unstructured, error) {
+	client, err := kube.GetDynamicClientForObjectRef(ref)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	obj, err := client.Get(ctx, ref.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("retrieving %s %s/%s: %w",
+			ref.Kind, ref.Namespace, ref.Name, err)
+	}
+	return obj, nil
+}
+
+// DeleteSubscription deletes the OLM Subscription referenced and the
+// ClusterServiceVersion it installed, thus removing the operator. A missing
+// subscription is not an error.
+func DeleteSubscription(
+	ctx context.Context,
+	kube Interface,
+	ref *corev1.ObjectReference,
+) error {
+	client, err := kube.GetDynamicClientForObjectRef(ref)
+	if err != nil {
+		return err
+	}
+	sub, err := client.Get(ctx, ref.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	csv, _, err := unstructured.NestedString(
+		sub.Object, "status", "installedCSV")
+	if err != nil {
+		return err
+	}
+	if err = DeleteObject(ctx, kube, ref); err != nil {
+		return err
+	}
+	if csv == "" {
+		return nil
+	}
+	return DeleteObject(ctx, kube, &corev1.ObjectReference{
+		APIVersion: "operators.coreos.com/v1alpha1",
+		Kind:       "ClusterServiceVersion",
+		Namespace:  ref.Namespace,
+		Name:       csv,
+	})
+}
@@ -0,0 +1,51 @@
+package printer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// HelmReleasePrinter prints the release information.
+func HelmReleasePrinter(w io.Writer, rel *release.Release) {
+	fmt.Fprintln(w, "#")
+	fmt.Fprintf(w, "#       Chart: %s\n", rel.Chart.Metadata.Name)
+	fmt.Fprintf(w, "#     Version: %s\n", rel.Chart.Metadata.Version)
+	fmt.Fprintf(w, "#      Status: %s\n", rel.Info.Status.String())
+	fmt.Fprintf(w, "#   Namespace: %s\n", rel.Namespace)
+	fmt.Fprintf(w, "#    Revision: %d\n", rel.Version)
+	fmt.Fprintf(w, "#     Updated: %s\n", rel.Info.LastDeployed.String())
+	fmt.Fprintln(w, "#")
+}
+
+// HelmReleaseNotesPrinter prints the release notes.
+func HelmReleaseNotesPrinter(w io.Writer, rel *release.Release) {
+	if rel.Info.Notes != "" {
+		fmt.Fprintf(w, "#\n# Notes\n#\n\n")
+		fmt.Fprintln(w, rel.Info.Notes)
+	}
+}
+
+// HelmExtendedReleasePrinter prints the release information, including the
+// manifest and hooks.
+func HelmExtendedReleasePrinter(w io.Writer, rel *release.Release) {
+	fmt.Fprintf(w, "#\n# Manifest\n#\n\n")
+	fmt.Fprint(w, rel.Manifest)
+
+	if len(rel.Hooks) > 0 {
+		fmt.Fprintf(w, "#\n# Hooks\n#\n")
+		for _, hook := range rel.Hooks {
+			fmt.Fprintf(w, "---\n%s\n", hook.Manifest)
+		}
+	}
+}
+
+// ValuesPrinter prints the values in a map as properties.
+func ValuesPrinter(w io.Writer, title string, vals map[string]interface{}) {
+	fmt.Fprintf(w, "#\n# %s\n#\n\n", title)
+	properties := new(strings.Builder)
+	valuesToProperties(vals, "", properties)
+	printProperties(w, properties, " * ")
+}
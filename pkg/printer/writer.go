@@ -0,0 +1,81 @@
+package printer
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// SyncWriter serializes the writes on the underlying writer, shared by
+// concurrent producers.
+type SyncWriter struct {
+	mu  sync.Mutex // serializes writes
+	out io.Writer  // underlying writer
+}
+
+var _ io.Writer = (*SyncWriter)(nil)
+
+// Write writes the payload on the underlying writer, exclusively.
+func (s *SyncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.out.Write(p)
+}
+
+// NewSyncWriter instantiates a SyncWriter for the informed writer.
+func NewSyncWriter(out io.Writer) *SyncWriter {
+	return &SyncWriter{out: out}
+}
+
+// PrefixWriter prefixes every line written with a fixed prefix, complete lines
+// are written at once on the underlying writer, so the output of concurrent
+// producers sharing a SyncWriter is interleaved line by line.
+type PrefixWriter struct {
+	mu     sync.Mutex // guards the buffer
+	out    io.Writer  // underlying writer
+	prefix []byte     // line prefix
+	buf    []byte     // incomplete line
+}
+
+var _ io.Writer = (*PrefixWriter)(nil)
+
+// Write buffers the payload, and writes the complete lines prefixed.
+func (p *PrefixWriter) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.buf = append(p.buf, b...)
+	var lines []byte
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			break
+		}
+		lines = append(lines, p.prefix...)
+		lines = append(lines, p.buf[:i+1]...)
+		p.buf = p.buf[i+1:]
+	}
+	if len(lines) > 0 {
+		if _, err := p.out.Write(lines); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Flush writes the last incomplete line, when any.
+func (p *PrefixWriter) Flush() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.buf) == 0 {
+		return nil
+	}
+	line := append(append([]byte{}, p.prefix...), p.buf...)
+	p.buf = nil
+	_, err := p.out.Write(append(line, '\n'))
+	return err
+}
+
+// NewPrefixWriter instantiates a PrefixWriter for the informed writer.
+func NewPrefixWriter(out io.Writer, prefix string) *PrefixWriter {
+	return &PrefixWriter{out: out, prefix: []byte(prefix)}
+}
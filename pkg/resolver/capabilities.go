@@ -0,0 +1,309 @@
+package resolver
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/k8s"
+
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/chart"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// ErrMissingCapabilities the cluster lacks the capabilities required by one or
+// more charts in the topology.
+var ErrMissingCapabilities = errors.New("missing cluster capabilities")
+
+// API represents a Kubernetes API kind, declared as "group/version/Kind", or
+// "group/Kind" for any version. The core group is declared as "core", e.g.
+// "core/v1/ConfigMap".
+type API struct {
+	Group   string // API group, empty for the core group
+	Version string // API version, empty for any version
+	Kind    string // resource kind
+}
+
+// String returns the API on its annotation notation.
+func (a API) String() string {
+	group := a.Group
+	if group == "" {
+		group = "core"
+	}
+	if a.Version == "" {
+		return fmt.Sprintf("%s/%s", group, a.Kind)
+	}
+	return fmt.Sprintf("%s/%s/%s", group, a.Version, a.Kind)
+}
+
+// anyVersion returns the API without version.
+func (a API) anyVersion() API {
+	return API{Group: a.Group, Kind: a.Kind}
+}
+
+// ParseAPI parses the API notation, "group/version/Kind" or "group/Kind".
+func ParseAPI(s string) (API, error) {
+	parts := strings.Split(strings.TrimSpace(s), "/")
+	a := API{}
+	switch len(parts) {
+	case 2:
+		a.Group, a.Kind = parts[0], parts[1]
+	case 3:
+		a.Group, a.Version, a.Kind = parts[0], parts[1], parts[2]
+	default:
+		return a, fmt.Errorf(
+			"invalid API %q, expected \"group/version/Kind\" or \"group/Kind\"", s)
+	}
+	if a.Group == "" || a.Kind == "" || (len(parts) == 3 && a.Version == "") {
+		return a, fmt.Errorf(
+			"invalid API %q, expected \"group/version/Kind\" or \"group/Kind\"", s)
+	}
+	if a.Group == "core" {
+		a.Group = ""
+	}
+	return a, nil
+}
+
+// parseAPIs parses the comma separated APIs of the informed annotation.
+func parseAPIs(annotation, value string) ([]API, error) {
+	apis := []API{}
+	for _, entry := range commaSeparatedToSlice(value) {
+		a, err := ParseAPI(entry)
+		if err != nil {
+			return nil, fmt.Errorf("annotation %q: %w", annotation, err)
+		}
+		apis = append(apis, a)
+	}
+	return apis, nil
+}
+
+// crdAPIs returns the APIs defined by the CustomResourceDefinitions shipped on
+// the chart "crds" directory, one per served version.
+func crdAPIs(hc *chart.Chart) ([]API, error) {
+	type crd struct {
+		Kind string `yaml:"kind"`
+		Spec struct {
+			Group string `yaml:"group"`
+			Names struct {
+				Kind string `yaml:"kind"`
+			} `yaml:"names"`
+			Versions []struct {
+				Name string `yaml:"name"`
+			} `yaml:"versions"`
+		} `yaml:"spec"`
+	}
+	apis := []API{}
+	for _, obj := range hc.CRDObjects() {
+		dec := yaml.NewDecoder(bytes.NewReader(obj.File.Data))
+		for {
+			var c crd
+			err := dec.Decode(&c)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("chart %q CRD %q: %w",
+					hc.Name(), obj.Filename, err)
+			}
+			if c.Kind != "CustomResourceDefinition" {
+				continue
+			}
+			if len(c.Spec.Versions) == 0 {
+				apis = append(apis, API{
+					Group: c.Spec.Group, Kind: c.Spec.Names.Kind,
+				})
+			}
+			for _, v := range c.Spec.Versions {
+				apis = append(apis, API{
+					Group: c.Spec.Group, Version: v.Name, Kind: c.Spec.Names.Kind,
+				})
+			}
+		}
+	}
+	return apis, nil
+}
+
+// features maps the cluster features to the API group which denotes them.
+var features = map[string]string{
+	"console":    "console.openshift.io",
+	"monitoring": "monitoring.coreos.com",
+	"olm":        "operators.coreos.com",
+	"projects":   "project.openshift.io",
+	"routes":     "route.openshift.io",
+}
+
+// Distribution names.
+const (
+	DistributionOpenShift  = "openshift"
+	DistributionKubernetes = "kubernetes"
+)
+
+// Capabilities represents what the cluster offers to the charts: the API kinds
+// served, the Kubernetes distribution and version, and the features derived
+// from the API groups.
+type Capabilities struct {
+	apis map[API]bool // served APIs, with and without version
+
+	Distribution      string   // "openshift" or "kubernetes"
+	KubernetesVersion string   // Kubernetes version, e.g. "1.31"
+	KubernetesMinor   int      // Kubernetes minor version number
+	Features          []string // cluster features, sorted
+}
+
+// Served checks whether the cluster serves the API, any version when the API
+// version is empty.
+func (c *Capabilities) Served(a API) bool {
+	return c.apis[a]
+}
+
+// variables returns the "cluster" CEL context variable.
+func (c *Capabilities) variables() map[string]any {
+	features := make([]any, 0, len(c.Features))
+	for _, f := range c.Features {
+		features = append(features, f)
+	}
+	return map[string]any{
+		"distribution":      c.Distribution,
+		"kubernetesVersion": c.KubernetesVersion,
+		"kubernetesMinor":   c.KubernetesMinor,
+		"features":          features,
+	}
+}
+
+// Verify asserts the capabilities required by each dependency in the topology,
+// in order. The APIs required must be served by the cluster, or provided by a
+// preceding dependency, either by its CRDs or "provides-apis" annotation. The
+// "requires-cluster" expressions are evaluated against the context variables,
+// where the "cluster" variable describes these capabilities. All unmet
+// requirements are reported at once.
+func (c *Capabilities) Verify(t *Topology, vars *Context) error {
+	if vars == nil {
+		vars = NewContext(nil, "")
+	}
+	withCluster := *vars
+	withCluster.Cluster = c.variables()
+	cel, err := NewCEL()
+	if err != nil {
+		return err
+	}
+	cel.SetContext(&withCluster)
+
+	// The APIs provided by the preceding dependencies, and the ones declared
+	// without version, which satisfy any version.
+	provided := map[API]bool{}
+	anyVersion := map[API]bool{}
+	issues := []string{}
+	err = t.Walk(func(name string, d Dependency) error {
+		required, err := d.RequiresAPIs()
+		if err != nil {
+			return err
+		}
+		for _, a := range required {
+			if c.Served(a) || provided[a] || anyVersion[a.anyVersion()] {
+				continue
+			}
+			issues = append(issues, fmt.Sprintf(
+				"%s: API %s is not served by the cluster, nor provided by a "+
+					"preceding chart", name, a))
+		}
+		if expression := d.RequiresCluster(); expression != "" {
+			ok, err := cel.Enabled(expression)
+			if err != nil {
+				return fmt.Errorf("dependency %q: requires-cluster: %w", name, err)
+			}
+			if !ok {
+				issues = append(issues, fmt.Sprintf(
+					"%s: requires-cluster %q is not satisfied by the %s %s "+
+						"cluster (features: %s)",
+					name, expression, c.Distribution, c.KubernetesVersion,
+					strings.Join(c.Features, ", ")))
+			}
+		}
+		apis, err := d.ProvidesAPIs()
+		if err != nil {
+			return err
+		}
+		for _, a := range apis {
+			provided[a] = true
+			provided[a.anyVersion()] = true
+			if a.Version == "" {
+				anyVersion[a] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(issues) > 0 {
+		return fmt.Errorf("%w:\n\n  - %s",
+			ErrMissingCapabilities, strings.Join(issues, "\n  - "))
+	}
+	return nil
+}
+
+// digits matches the leading digits of the version numbers reported by the
+// cluster, e.g. "31+".
+var digits = regexp.MustCompile(`^\d+`)
+
+// NewCapabilities inspects the cluster through the discovery client, the API
+// groups which fail discovery, e.g. unavailable aggregated APIs, are skipped.
+func NewCapabilities(kube k8s.Interface) (*Capabilities, error) {
+	dc, err := kube.DiscoveryClient("")
+	if err != nil {
+		return nil, err
+	}
+	info, err := dc.ServerVersion()
+	if err != nil {
+		return nil, err
+	}
+	_, resources, err := dc.ServerGroupsAndResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, err
+	}
+
+	c := &Capabilities{
+		apis:         map[API]bool{},
+		Distribution: DistributionKubernetes,
+		Features:     []string{},
+	}
+	major := digits.FindString(info.Major)
+	minor := digits.FindString(info.Minor)
+	c.KubernetesVersion = fmt.Sprintf("%s.%s", major, minor)
+	c.KubernetesMinor, _ = strconv.Atoi(minor)
+
+	groups := map[string]bool{}
+	for _, list := range resources {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		groups[gv.Group] = true
+		for _, r := range list.APIResources {
+			// Skipping the subresources, e.g. "deployments/scale".
+			if strings.Contains(r.Name, "/") {
+				continue
+			}
+			a := API{Group: gv.Group, Version: gv.Version, Kind: r.Kind}
+			c.apis[a] = true
+			c.apis[a.anyVersion()] = true
+		}
+	}
+	if groups["config.openshift.io"] {
+		c.Distribution = DistributionOpenShift
+	}
+	for feature, group := range features {
+		if groups[group] {
+			c.Features = append(c.Features, feature)
+		}
+	}
+	slices.Sort(c.Features)
+	return c, nil
+}
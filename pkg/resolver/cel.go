@@ -0,0 +1,292 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/config"
+	"github.com/redhat-appstudio/tssc-cli/pkg/k8s"
+
+	"github.com/google/cel-go/cel"
+)
+
+// CEL represents the CEL environment with provided integration names, the
+// integrations present in the cluster are represented by a map of integration
+// name and boolean, indicating the integration is configured in the cluster.
+// Besides the integrations, the expressions can reference the variables in the
+// Context: "settings", "products", "openshift" and "cluster".
+type CEL struct {
+	env  *cel.Env // all known integrations names, and context variables
+	vars *Context // context variables
+}
+
+// Context represents the variables available to the CEL expressions, besides
+// the integration names. The installer settings, the products indexed by their
+// lowercase key name, the OpenShift cluster facts, and the cluster capabilities.
+type Context struct {
+	Settings  map[string]any // "settings"
+	Products  map[string]any // "products"
+	OpenShift map[string]any // "openshift"
+	Cluster   map[string]any // "cluster"
+}
+
+// Context variable names.
+const (
+	celSettings  = "settings"
+	celProducts  = "products"
+	celOpenShift = "openshift"
+	celCluster   = "cluster"
+)
+
+// activation returns the context variables indexed by their CEL name.
+func (c *Context) activation() map[string]any {
+	if c == nil {
+		c = NewContext(nil, "")
+	}
+	return map[string]any{
+		celSettings:  c.Settings,
+		celProducts:  c.Products,
+		celOpenShift: c.OpenShift,
+		celCluster:   c.Cluster,
+	}
+}
+
+var (
+	// ErrInvalidExpression the expression is not a valid CEL expression.
+	ErrInvalidExpression = errors.New("invalid CEL expression")
+	// ErrMissingIntegrations one or more integrations aren't configured.
+	ErrMissingIntegrations = errors.New("missing integrations")
+)
+
+// SetContext sets the context variables used to evaluate the expressions.
+func (c *CEL) SetContext(vars *Context) {
+	c.vars = vars
+}
+
+// eval compiles and evaluates the expression against the configured integrations
+// and the context variables, returning the result and the referenced names.
+func (c *CEL) eval(
+	configured map[string]bool,
+	expression string,
+) (any, []string, error) {
+	// Instantiaging the AST with the informed expression, and checking for
+	// expression issues.
+	ast, issues := c.env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, nil, fmt.Errorf("%w: %q", ErrInvalidExpression, expression)
+	}
+
+	// Generating a checked AST, where the types are validated, this allows
+	// extracing the actual integration names referenced in the expression.
+	checkedAST, issues := c.env.Check(ast)
+	if issues != nil && issues.Err() != nil {
+		return nil, nil, fmt.Errorf("%w: %q", ErrInvalidExpression, expression)
+	}
+	referenced := []string{}
+	for _, ref := range checkedAST.NativeRep().ReferenceMap() {
+		if ref.Name != "" {
+			referenced = append(referenced, ref.Name)
+		}
+	}
+
+	// Generating the program from the AST, and evaluating it against the context
+	// based on the configured integrations and the context variables.
+	prg, err := c.env.Program(ast)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %q fails to compile: %w",
+			ErrInvalidExpression, expression, err)
+	}
+	evalContext := c.vars.activation()
+	for k, v := range configured {
+		evalContext[k] = v
+	}
+	result, _, err := prg.Eval(evalContext)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%q: %w", expression, err)
+	}
+	return result.Value(), referenced, nil
+}
+
+// References returns the names referenced by the expression, integration names
+// and context variables.
+func (c *CEL) References(expression string) ([]string, error) {
+	ast, issues := c.env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidExpression, expression)
+	}
+	references := []string{}
+	for _, ref := range ast.NativeRep().ReferenceMap() {
+		if ref.Name != "" {
+			references = append(references, ref.Name)
+		}
+	}
+	return references, nil
+}
+
+// Validate parses and type-checks the expression, without evaluating it. The
+// names which aren't known integrations are reported as undeclared references.
+func (c *CEL) Validate(expression string) error {
+	_, issues := c.env.Compile(expression)
+	if issues == nil || issues.Err() == nil {
+		return nil
+	}
+	messages := []string{}
+	for _, e := range issues.Errors() {
+		messages = append(messages, e.Message)
+	}
+	return fmt.Errorf("%w: %q: %s",
+		ErrInvalidExpression, expression, strings.Join(messages, "; "))
+}
+
+// Result evaluates the provided CEL expression against the current context of
+// integration names and a boolean indicating whether it's configured. It returns
+// whether the expression is satisfied, and when not, the referenced integrations
+// which aren't configured.
+func (c *CEL) Result(
+	configured map[string]bool,
+	expression string,
+) (bool, []string, error) {
+	result, referenced, err := c.eval(configured, expression)
+	if err != nil {
+		return false, nil, err
+	}
+
+	// All expressions must evaluate to true, meaning all required integrations
+	// are configured.
+	if result == true {
+		return true, nil, nil
+	}
+
+	// Using the referenced integration names to determine which integrations are
+	// missing, as in should be configured in the cluster but aren't found. The
+	// context variables are not integrations.
+	missing := []string{}
+	for _, ref := range referenced {
+		if configured, known := configured[ref]; known && !configured {
+			missing = append(missing, ref)
+		}
+	}
+	slices.Sort(missing)
+	return false, missing, nil
+}
+
+// Enabled evaluates the provided CEL expression against the context variables,
+// the expression must result in a boolean.
+func (c *CEL) Enabled(expression string) (bool, error) {
+	result, _, err := c.eval(nil, expression)
+	if err != nil {
+		return false, err
+	}
+	enabled, ok := result.(bool)
+	if !ok {
+		return false, fmt.Errorf("%w: %q: results in %T instead of bool",
+			ErrInvalidExpression, expression, result)
+	}
+	return enabled, nil
+}
+
+// Evaluate evaluates the provided CEL expression, as Result does, returning an
+// error listing the missing integrations when the expression isn't satisfied.
+func (c *CEL) Evaluate(configured map[string]bool, expression string) error {
+	satisfied, missing, err := c.Result(configured, expression)
+	if err != nil || satisfied {
+		return err
+	}
+	return fmt.Errorf("%w: %s",
+		ErrMissingIntegrations, strings.Join(missing, ", "))
+}
+
+// NewContext creates the CEL context variables from the installer configuration,
+// when informed, and the OpenShift cluster version, empty when unknown.
+func NewContext(cfg *config.Config, openShiftVersion string) *Context {
+	c := &Context{
+		Settings:  map[string]any{},
+		Products:  map[string]any{},
+		OpenShift: map[string]any{},
+		Cluster: map[string]any{
+			"distribution":      "",
+			"kubernetesVersion": "",
+			"kubernetesMinor":   0,
+			"features":          []any{},
+		},
+	}
+	if cfg != nil {
+		maps.Copy(c.Settings, cfg.Installer.Settings)
+		for _, p := range cfg.Installer.Products {
+			properties := p.Properties
+			if properties == nil {
+				properties = map[string]any{}
+			}
+			c.Products[strings.ToLower(p.KeyName())] = map[string]any{
+				"name":       p.Name,
+				"enabled":    p.Enabled,
+				"namespace":  p.GetNamespace(),
+				"properties": properties,
+			}
+		}
+	}
+	// The major and minor version numbers are zero when the cluster version is
+	// unknown, e.g. on vanilla Kubernetes.
+	c.OpenShift["version"] = openShiftVersion
+	c.OpenShift["majorVersion"] = 0
+	c.OpenShift["minorVersion"] = 0
+	parts := strings.Split(openShiftVersion, ".")
+	if len(parts) >= 2 {
+		if major, err := strconv.Atoi(parts[0]); err == nil {
+			c.OpenShift["majorVersion"] = major
+		}
+		if minor, err := strconv.Atoi(parts[1]); err == nil {
+			c.OpenShift["minorVersion"] = minor
+		}
+	}
+	return c
+}
+
+// NewContextFromCluster creates the CEL context variables from the installer
+// configuration and the cluster. On vanilla Kubernetes clusters the OpenShift
+// version is empty, and the cluster capabilities are empty when the discovery
+// fails.
+func NewContextFromCluster(
+	ctx context.Context,
+	kube k8s.Interface,
+	cfg *config.Config,
+) *Context {
+	version, err := k8s.GetOpenShiftVersion(ctx, kube)
+	if err != nil {
+		version = ""
+	}
+	c := NewContext(cfg, version)
+	if caps, err := NewCapabilities(kube); err == nil {
+		c.Cluster = caps.variables()
+	}
+	return c
+}
+
+// NewCEL creates a new CEL instance with the all valid integration names. These
+// names are considered variables in the CEL expression, limiting the scope of the
+// expression to only valid integrations, besides the context variables.
+func NewCEL(integrationNames ...string) (*CEL, error) {
+	// Registering the context variables, and all integration names as options,
+	// boolean variables.
+	dynMap := cel.MapType(cel.StringType, cel.DynType)
+	options := []cel.EnvOption{
+		cel.Variable(celSettings, dynMap),
+		cel.Variable(celProducts, dynMap),
+		cel.Variable(celOpenShift, dynMap),
+		cel.Variable(celCluster, dynMap),
+	}
+	for _, option := range integrationNames {
+		options = append(options, cel.Variable(option, cel.BoolType))
+	}
+	// Creating a CEL environment using the integration names as options.
+	env, err := cel.NewEnv(options...)
+	if err != nil {
+		return nil, err
+	}
+	return &CEL{env: env, vars: NewContext(nil, "")}, nil
+}
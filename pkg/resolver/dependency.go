@@ -0,0 +1,225 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/annotations"
+
+	"github.com/Masterminds/semver/v3"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// Dependency represent a installer Dependency, which consists of a Helm chart
+// instance, namespace and metadata. The relevant Helm chart metadata is read by
+// helper methods.
+type Dependency struct {
+	chart     *chart.Chart // helm chart instance
+	namespace string       // target namespace
+}
+
+// Dependencies represents a slice of Dependency instances.
+type Dependencies []Dependency
+
+// LoggerWith decorates the logger with dependency information, when the Helm
+// chart is set.
+func (d *Dependency) LoggerWith(logger *slog.Logger) *slog.Logger {
+	if d.chart == nil {
+		return logger
+	}
+	return logger.With(
+		"dependency-name", d.Name(),
+		"dependency-namespace", d.Namespace(),
+	)
+}
+
+// Chart exposes the Helm chart instance.
+func (d *Dependency) Chart() *chart.Chart {
+	return d.chart
+}
+
+// Name returns the name of the Helm chart.
+func (d *Dependency) Name() string {
+	return d.chart.Name()
+}
+
+// Namespace returns the namespace.
+func (d *Dependency) Namespace() string {
+	return d.namespace
+}
+
+// SetNamespace sets the namespace for this dependency.
+func (d *Dependency) SetNamespace(namespace string) {
+	d.namespace = namespace
+}
+
+// getAnnotation retrieves a chart annotation value, returns empty for unknown
+// annotation names.
+func (d *Dependency) getAnnotation(annotation string) string {
+	if v, exists := d.chart.Metadata.Annotations[annotation]; exists {
+		return v
+	}
+	return ""
+}
+
+// DependsOnConstraint represents a "depends-on" entry, the chart name followed
+// by an optional semantic version constraint, e.g. "tssc-infrastructure >=1.9.0".
+type DependsOnConstraint struct {
+	Name       string              // chart name
+	Constraint *semver.Constraints // version constraint, nil when absent
+}
+
+// ErrIncompatibleDependency the chart version doesn't satisfy the constraint.
+var ErrIncompatibleDependency = errors.New("incompatible dependency version")
+
+// Check asserts the informed chart version satisfies the constraint.
+func (c *DependsOnConstraint) Check(version string) error {
+	if c.Constraint == nil {
+		return nil
+	}
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return fmt.Errorf("%w: %q version %q: %w",
+			ErrIncompatibleDependency, c.Name, version, err)
+	}
+	if !c.Constraint.Check(v) {
+		return fmt.Errorf("%w: %q version %q, required %q",
+			ErrIncompatibleDependency, c.Name, version, c.Constraint)
+	}
+	return nil
+}
+
+// DependsOn returns a slice of dependencies names from the chart's annotation,
+// without the version constraints.
+func (d *Dependency) DependsOn() []string {
+	dependsOn := d.getAnnotation(annotations.DependsOn)
+	if dependsOn == "" {
+		return nil
+	}
+	names := []string{}
+	for _, entry := range commaSeparatedToSlice(dependsOn) {
+		names = append(names, strings.Fields(entry)[0])
+	}
+	return names
+}
+
+// SoftDependsOn returns the names of the charts this dependency is deployed
+// after, only when they are part of the topology. Soft dependencies don't
+// require the charts.
+func (d *Dependency) SoftDependsOn() []string {
+	return commaSeparatedToSlice(d.getAnnotation(annotations.SoftDependsOn))
+}
+
+// DependsOnConstraints returns the "depends-on" entries with their version
+// constraints, it fails on invalid constraints.
+func (d *Dependency) DependsOnConstraints() ([]DependsOnConstraint, error) {
+	constraints := []DependsOnConstraint{}
+	for _, entry := range commaSeparatedToSlice(
+		d.getAnnotation(annotations.DependsOn),
+	) {
+		fields := strings.Fields(entry)
+		c := DependsOnConstraint{Name: fields[0]}
+		if len(fields) > 1 {
+			var err error
+			constraint := strings.Join(fields[1:], " ")
+			if c.Constraint, err = semver.NewConstraint(constraint); err != nil {
+				return nil, fmt.Errorf(
+					"invalid version constraint %q for %q in annotation %q: %w",
+					constraint, c.Name, annotations.DependsOn, err)
+			}
+		}
+		constraints = append(constraints, c)
+	}
+	return constraints, nil
+}
+
+// Weight returns the weight of this dependency. If no weight is specified, zero
+// is returned. The weight must be specified as an integer value.
+func (d *Dependency) Weight() (int, error) {
+	if v, exists := d.chart.Metadata.Annotations[annotations.Weight]; exists {
+		w, err := strconv.Atoi(v)
+		if err != nil {
+			return -1, fmt.Errorf(
+				"invalid value %q for annotation %q", v, annotations.Weight)
+		}
+		return w, nil
+	}
+	return 0, nil
+}
+
+// ProductName returns the product name from the chart annotations.
+func (d *Dependency) ProductName() string {
+	return d.getAnnotation(annotations.ProductName)
+}
+
+// UseProductNamespace returns the product namespace from the chart annotations.
+func (d *Dependency) UseProductNamespace() string {
+	return d.getAnnotation(annotations.UseProductNamespace)
+}
+
+// IntegrationsProvided returns the integrations provided.
+func (d *Dependency) IntegrationsProvided() []string {
+	provided := d.getAnnotation(annotations.IntegrationsProvided)
+	return commaSeparatedToSlice(provided)
+}
+
+// IntegrationsRequired returns the integrations required.
+func (d *Dependency) IntegrationsRequired() string {
+	return d.getAnnotation(annotations.IntegrationsRequired)
+}
+
+// EnabledIf returns the CEL expression conditioning the chart inclusion.
+func (d *Dependency) EnabledIf() string {
+	return d.getAnnotation(annotations.EnabledIf)
+}
+
+// RequiresAPIs returns the API kinds the chart requires from the cluster, or
+// from the preceding charts in the topology.
+func (d *Dependency) RequiresAPIs() ([]API, error) {
+	return parseAPIs(
+		annotations.RequiresAPIs, d.getAnnotation(annotations.RequiresAPIs))
+}
+
+// ProvidesAPIs returns the API kinds the chart delivers to the charts after it,
+// the CRDs shipped by the chart and the ones declared on the annotation, e.g.
+// installed by an operator subscription.
+func (d *Dependency) ProvidesAPIs() ([]API, error) {
+	apis, err := parseAPIs(
+		annotations.ProvidesAPIs, d.getAnnotation(annotations.ProvidesAPIs))
+	if err != nil {
+		return nil, err
+	}
+	crds, err := crdAPIs(d.chart)
+	if err != nil {
+		return nil, err
+	}
+	return append(apis, crds...), nil
+}
+
+// RequiresCluster returns the CEL expression the cluster must satisfy.
+func (d *Dependency) RequiresCluster() string {
+	return d.getAnnotation(annotations.RequiresCluster)
+}
+
+// FailurePolicy returns the action taken when the chart fails to deploy, empty
+// when the global policy applies.
+func (d *Dependency) FailurePolicy() string {
+	return d.getAnnotation(annotations.FailurePolicy)
+}
+
+// NewDependency creates a new Dependency for the Helm chart and initially using
+// empty target namespace.
+func NewDependency(hc *chart.Chart) *Dependency {
+	return &Dependency{chart: hc}
+}
+
+// NewDependencyWithNamespace creates a new Dependency for the Helm chart and sets
+// the target namespace.
+func NewDependencyWithNamespace(hc *chart.Chart, ns string) *Dependency {
+	d := NewDependency(hc)
+	d.SetNamespace(ns)
+	return d
+}
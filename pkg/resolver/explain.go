@@ -0,0 +1,226 @@
+package resolver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Explanation describes why a chart is, or isn't, part of the topology, which
+// rule chose its namespace, and what determines its deployment order.
+type Explanation struct {
+	Chart     string   `json:"chart" yaml:"chart"`
+	Included  bool     `json:"included" yaml:"included"`
+	Reasons   []string `json:"reasons" yaml:"reasons"`
+	Index     int      `json:"index,omitempty" yaml:"index,omitempty"`
+	Level     *int     `json:"level,omitempty" yaml:"level,omitempty"`
+	Namespace string   `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Rule      string   `json:"namespaceRule,omitempty" yaml:"namespaceRule,omitempty"`
+	Ordering  []string `json:"ordering,omitempty" yaml:"ordering,omitempty"`
+}
+
+// PrintText prints the explanation to the writer, human readable.
+func (e *Explanation) PrintText(w io.Writer) {
+	fmt.Fprintf(w, "Chart: %s\n", e.Chart)
+	if !e.Included {
+		fmt.Fprintf(w, "Included: no\n")
+	} else {
+		fmt.Fprintf(w, "Included: yes, index %d (level %d)\n",
+			e.Index, *e.Level)
+	}
+	fmt.Fprintf(w, "\nReasons:\n")
+	for _, r := range e.Reasons {
+		fmt.Fprintf(w, "  - %s\n", r)
+	}
+	if !e.Included {
+		return
+	}
+	fmt.Fprintf(w, "\nNamespace: %s\n  - %s\n", e.Namespace, e.Rule)
+	fmt.Fprintf(w, "\nOrdering:\n")
+	for _, o := range e.Ordering {
+		fmt.Fprintf(w, "  - %s\n", o)
+	}
+}
+
+// PrintJSON prints the explanation to the writer as JSON.
+func (e *Explanation) PrintJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(e)
+}
+
+// PrintYAML prints the explanation to the writer as YAML.
+func (e *Explanation) PrintYAML(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(e); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// excluded returns the reasons the dependency isn't part of the topology,
+// besides the ones recorded while resolving.
+func (r *Resolver) excluded(d *Dependency) ([]string, error) {
+	enabled, err := r.enabled(d)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return []string{fmt.Sprintf(
+			"enabled-if %q evaluates to false", d.EnabledIf())}, nil
+	}
+	if product := d.ProductName(); product != "" {
+		spec, err := r.cfg.GetProduct(product)
+		if err != nil {
+			return nil, err
+		}
+		if !spec.Enabled {
+			return []string{fmt.Sprintf(
+				"chart of the product %q, which is disabled", product)}, nil
+		}
+		return nil, nil
+	}
+	dependsOn := append(d.DependsOn(), d.SoftDependsOn()...)
+	if len(dependsOn) == 0 {
+		return []string{
+			"not required by any chart in the topology, and it does not " +
+				"depend on any chart (depends-on)",
+		}, nil
+	}
+	absent := []string{}
+	for _, name := range dependsOn {
+		if !r.topology.Contains(name) {
+			absent = append(absent, name)
+		}
+	}
+	if len(absent) == len(dependsOn) {
+		return []string{fmt.Sprintf(
+			"not required by any chart in the topology, and none of the "+
+				"charts it depends on are part of it: %s",
+			strings.Join(absent, ", "),
+		)}, nil
+	}
+	return []string{fmt.Sprintf(
+		"not required by any chart in the topology, the charts it depends on "+
+			"were not part of it when inspected: %s",
+		strings.Join(dependsOn, ", "),
+	)}, nil
+}
+
+// ordering describes the depends-on edges and weights which determine the
+// position of the dependency in the topology.
+func (r *Resolver) ordering(d *Dependency, index int) []string {
+	deps := r.topology.Dependencies()
+	position := func(name string) int {
+		for i := range deps {
+			if deps[i].Name() == name {
+				return i + 1
+			}
+		}
+		return -1
+	}
+	describe := func(i int) string {
+		return fmt.Sprintf("%s (#%d)", deps[i].Name(), i+1)
+	}
+
+	ordering := []string{}
+	after := []string{}
+	for _, name := range d.DependsOn() {
+		if p := position(name); p > 0 {
+			after = append(after, describe(p-1))
+		}
+	}
+	if len(after) > 0 {
+		ordering = append(ordering, fmt.Sprintf(
+			"deployed after the charts it depends on: %s",
+			strings.Join(after, ", ")))
+	} else {
+		ordering = append(ordering,
+			"does not depend on any chart in the topology")
+	}
+	soft := []string{}
+	for _, name := range d.SoftDependsOn() {
+		if p := position(name); p > 0 {
+			soft = append(soft, describe(p-1))
+		}
+	}
+	if len(soft) > 0 {
+		ordering = append(ordering, fmt.Sprintf(
+			"deployed after the charts it soft-depends on: %s",
+			strings.Join(soft, ", ")))
+	}
+
+	before := []string{}
+	for i := range deps {
+		if i+1 != index &&
+			(slices.Contains(deps[i].DependsOn(), d.Name()) ||
+				slices.Contains(deps[i].SoftDependsOn(), d.Name())) {
+			before = append(before, describe(i))
+		}
+	}
+	if len(before) > 0 {
+		ordering = append(ordering, fmt.Sprintf(
+			"deployed before the charts depending on it: %s",
+			strings.Join(before, ", ")))
+	}
+
+	weight, _ := d.Weight()
+	lighter := []string{}
+	for i := range deps[:index-1] {
+		if w, _ := deps[i].Weight(); w < weight {
+			lighter = append(lighter, describe(i))
+		}
+	}
+	if len(lighter) > 0 {
+		ordering = append(ordering, fmt.Sprintf(
+			"weight %d, deployed after the preceding charts with lower "+
+				"weight: %s", weight, strings.Join(lighter, ", ")))
+	} else {
+		ordering = append(ordering, fmt.Sprintf(
+			"weight %d, no preceding chart has a lower weight", weight))
+	}
+	return ordering
+}
+
+// Explain describes why the informed chart is, or isn't, part of the resolved
+// topology. It must be called after Resolve.
+func (r *Resolver) Explain(name string) (*Explanation, error) {
+	d, err := r.collection.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	e := &Explanation{Chart: name, Reasons: r.reasons[name]}
+	if e.Reasons == nil {
+		e.Reasons = []string{}
+	}
+	for level, deps := range r.topology.Levels() {
+		for i := range deps {
+			if deps[i].Name() == name {
+				e.Level = &level
+			}
+		}
+	}
+	for i, dep := range r.topology.Dependencies() {
+		if dep.Name() != name {
+			continue
+		}
+		e.Included = true
+		e.Index = i + 1
+		e.Namespace = dep.Namespace()
+		e.Rule = r.namespaceRules[name]
+		e.Ordering = r.ordering(&dep, e.Index)
+		return e, nil
+	}
+
+	reasons, err := r.excluded(d)
+	if err != nil {
+		return nil, err
+	}
+	e.Reasons = append(e.Reasons, reasons...)
+	return e, nil
+}
@@ -0,0 +1,432 @@
+package resolver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/annotations"
+	"github.com/redhat-appstudio/tssc-cli/pkg/chartfs"
+	"github.com/redhat-appstudio/tssc-cli/pkg/deployer"
+
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// ErrLintFindings the linter found issues on the charts or configuration.
+var ErrLintFindings = errors.New("lint issues found")
+
+// Finding represents an issue found by the linter, located on a file.
+type Finding struct {
+	File    string `json:"file"`            // file path
+	Line    int    `json:"line,omitempty"`  // line number, when known
+	Chart   string `json:"chart,omitempty"` // chart name, when any
+	Message string `json:"message"`         // issue description
+}
+
+// String returns the finding formatted as "file:line: message".
+func (f Finding) String() string {
+	location := f.File
+	if f.Line > 0 {
+		location = fmt.Sprintf("%s:%d", f.File, f.Line)
+	}
+	return fmt.Sprintf("%s: %s", location, f.Message)
+}
+
+// lintChart represents a chart inspected by the linter, with the location of its
+// annotations.
+type lintChart struct {
+	dep   *Dependency    // chart dependency
+	file  string         // "Chart.yaml" path
+	lines map[string]int // annotation line numbers
+}
+
+// line returns the line of the informed annotation, or of the annotations block
+// when the annotation isn't present.
+func (c *lintChart) line(annotation string) int {
+	if l, exists := c.lines[annotation]; exists {
+		return l
+	}
+	return c.lines[""]
+}
+
+// Linter inspects the installer Helm charts annotations, and the installer
+// configuration, reporting inconsistencies which would otherwise surface only at
+// deployment time, or be silently ignored.
+type Linter struct {
+	cfs              chartfs.Interface // installer filesystem
+	configPath       string            // configuration file path
+	appName          string            // configuration root key
+	integrationNames []string          // known integration names
+
+	charts   []*lintChart   // inspected charts
+	products map[string]int // configuration products and their line
+	findings []Finding      // issues found
+}
+
+// report records a finding on the chart annotation.
+func (l *Linter) report(c *lintChart, annotation, format string, a ...any) {
+	l.findings = append(l.findings, Finding{
+		File:    c.file,
+		Line:    c.line(annotation),
+		Chart:   c.dep.Name(),
+		Message: fmt.Sprintf(format, a...),
+	})
+}
+
+// annotationLines returns the line numbers of the "Chart.yaml" annotations, the
+// annotations block line is stored with an empty key.
+func annotationLines(payload []byte) (map[string]int, error) {
+	lines := map[string]int{}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(payload, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return lines, nil
+	}
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "annotations" {
+			continue
+		}
+		lines[""] = root.Content[i].Line
+		block := root.Content[i+1]
+		for j := 0; j+1 < len(block.Content); j += 2 {
+			lines[block.Content[j].Value] = block.Content[j].Line
+		}
+	}
+	return lines, nil
+}
+
+// loadCharts finds and loads all Helm charts on the filesystem, as the chart
+// filesystem does, keeping their locations.
+func (l *Linter) loadCharts() error {
+	return fs.WalkDir(l.cfs, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		file := path.Join(name, chartutil.ChartfileName)
+		payload, err := l.cfs.ReadFile(file)
+		if err != nil {
+			return nil
+		}
+		lines, err := annotationLines(payload)
+		if err != nil {
+			l.findings = append(l.findings, Finding{
+				File: file, Message: fmt.Sprintf("invalid YAML: %s", err),
+			})
+			return nil
+		}
+		hc, err := l.cfs.GetChartFiles(name)
+		if err != nil {
+			l.findings = append(l.findings, Finding{
+				File: file, Message: fmt.Sprintf("invalid chart: %s", err),
+			})
+			return nil
+		}
+		l.charts = append(l.charts, &lintChart{
+			dep:   NewDependency(hc),
+			file:  file,
+			lines: lines,
+		})
+		return nil
+	})
+}
+
+// loadProducts reads the product names from the configuration file, and their
+// line numbers.
+func (l *Linter) loadProducts() error {
+	payload, err := l.cfs.ReadFile(l.configPath)
+	if err != nil {
+		return err
+	}
+	var doc yaml.Node
+	if err = yaml.Unmarshal(payload, &doc); err != nil {
+		return fmt.Errorf("%s: %w", l.configPath, err)
+	}
+	l.products = map[string]int{}
+	// Navigating "<appName>.products[].name" on the document nodes.
+	value := func(n *yaml.Node, key string) *yaml.Node {
+		if n == nil || n.Kind != yaml.MappingNode {
+			return nil
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value == key {
+				return n.Content[i+1]
+			}
+		}
+		return nil
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	products := value(value(doc.Content[0], l.appName), "products")
+	if products == nil {
+		return nil
+	}
+	for _, p := range products.Content {
+		if name := value(p, "name"); name != nil {
+			l.products[name.Value] = name.Line
+		}
+	}
+	return nil
+}
+
+// lintNames reports duplicated chart and product names.
+func (l *Linter) lintNames() {
+	charts := map[string]string{}
+	products := map[string]string{}
+	for _, c := range l.charts {
+		if file, exists := charts[c.dep.Name()]; exists {
+			l.report(c, "", "duplicate chart name %q, also in %s",
+				c.dep.Name(), file)
+		}
+		charts[c.dep.Name()] = c.file
+		product := c.dep.ProductName()
+		if product == "" {
+			continue
+		}
+		if file, exists := products[product]; exists {
+			l.report(c, annotations.ProductName,
+				"duplicate product name %q, also in %s", product, file)
+		}
+		products[product] = c.file
+		if _, exists := l.products[product]; !exists {
+			l.report(c, annotations.ProductName,
+				"product %q is not in %s", product, l.configPath)
+		}
+	}
+	for product, line := range l.products {
+		if _, exists := products[product]; !exists {
+			l.findings = append(l.findings, Finding{
+				File:    l.configPath,
+				Line:    line,
+				Message: fmt.Sprintf("product %q has no chart", product),
+			})
+		}
+	}
+}
+
+// lintAnnotations reports the invalid annotations of each chart, the CEL
+// environments validate "integrations-required", and "enabled-if" and
+// "requires-cluster" respectively.
+func (l *Linter) lintAnnotations(cel, enabledIf *CEL) {
+	names := map[string]bool{}
+	versions := map[string]string{}
+	for _, c := range l.charts {
+		names[c.dep.Name()] = true
+		versions[c.dep.Name()] = c.dep.Chart().Metadata.Version
+	}
+	for _, c := range l.charts {
+		if _, err := c.dep.Weight(); err != nil {
+			l.report(c, annotations.Weight, "%s", err)
+		}
+		for _, dependsOn := range c.dep.DependsOn() {
+			if !names[dependsOn] {
+				l.report(c, annotations.DependsOn,
+					"unknown chart %q in depends-on", dependsOn)
+			}
+			if dependsOn == c.dep.Name() {
+				l.report(c, annotations.DependsOn,
+					"chart depends on itself")
+			}
+		}
+		for _, soft := range c.dep.SoftDependsOn() {
+			if !names[soft] {
+				l.report(c, annotations.SoftDependsOn,
+					"unknown chart %q in soft-depends-on", soft)
+			}
+			if soft == c.dep.Name() {
+				l.report(c, annotations.SoftDependsOn,
+					"chart soft-depends on itself")
+			}
+			if slices.Contains(c.dep.DependsOn(), soft) {
+				l.report(c, annotations.SoftDependsOn,
+					"chart %q is in both depends-on and soft-depends-on", soft)
+			}
+		}
+		if constraints, err := c.dep.DependsOnConstraints(); err != nil {
+			l.report(c, annotations.DependsOn, "%s", err)
+		} else {
+			for _, dc := range constraints {
+				version, exists := versions[dc.Name]
+				if !exists {
+					continue
+				}
+				if err = dc.Check(version); err != nil {
+					l.report(c, annotations.DependsOn, "%s", err)
+				}
+			}
+		}
+		if product := c.dep.UseProductNamespace(); product != "" {
+			if _, exists := l.products[product]; !exists {
+				l.report(c, annotations.UseProductNamespace,
+					"unknown product %q in use-product-namespace, not in %s",
+					product, l.configPath)
+			}
+		}
+		for _, provided := range c.dep.IntegrationsProvided() {
+			if !slices.Contains(l.integrationNames, provided) {
+				l.report(c, annotations.IntegrationsProvided,
+					"unknown integration %q in integrations-provided", provided)
+			}
+		}
+		if required := c.dep.IntegrationsRequired(); required != "" {
+			if err := cel.Validate(required); err != nil {
+				l.report(c, annotations.IntegrationsRequired,
+					"integrations-required: %s", err)
+			}
+		}
+		if expression := c.dep.EnabledIf(); expression != "" {
+			if err := enabledIf.Validate(expression); err != nil {
+				l.report(c, annotations.EnabledIf, "enabled-if: %s", err)
+			}
+		}
+		if policy := c.dep.FailurePolicy(); policy != "" {
+			if err := deployer.ValidateFailurePolicy(policy); err != nil {
+				l.report(c, annotations.FailurePolicy, "%s", err)
+			}
+		}
+		if _, err := c.dep.RequiresAPIs(); err != nil {
+			l.report(c, annotations.RequiresAPIs, "%s", err)
+		}
+		if _, err := c.dep.ProvidesAPIs(); err != nil {
+			l.report(c, annotations.ProvidesAPIs, "%s", err)
+		}
+		if expression := c.dep.RequiresCluster(); expression != "" {
+			if err := enabledIf.Validate(expression); err != nil {
+				l.report(c, annotations.RequiresCluster,
+					"requires-cluster: %s", err)
+			}
+		}
+	}
+}
+
+// lintCycles reports the circular dependencies between charts, including the
+// soft dependencies, each cycle is reported once, on the first chart of the
+// cycle.
+func (l *Linter) lintCycles() {
+	byName := map[string]*lintChart{}
+	for _, c := range l.charts {
+		byName[c.dep.Name()] = c
+	}
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[string]int{}
+	stack := []string{}
+	reported := map[string]bool{}
+
+	var visit func(name string)
+	visit = func(name string) {
+		state[name] = visiting
+		stack = append(stack, name)
+		dep := byName[name].dep
+		for _, next := range append(dep.DependsOn(), dep.SoftDependsOn()...) {
+			if _, exists := byName[next]; !exists || next == name {
+				continue
+			}
+			switch state[next] {
+			case unvisited:
+				visit(next)
+			case visiting:
+				cycle := slices.Clone(stack[slices.Index(stack, next):])
+				// The cycle is identified by its members, regardless of the
+				// chart it's found from.
+				key := slices.Clone(cycle)
+				slices.Sort(key)
+				if id := strings.Join(key, ","); !reported[id] {
+					reported[id] = true
+					l.report(byName[next], annotations.DependsOn,
+						"circular dependency: %s -> %s",
+						strings.Join(cycle, " -> "), next)
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = visited
+	}
+	for _, c := range l.charts {
+		if state[c.dep.Name()] == unvisited {
+			visit(c.dep.Name())
+		}
+	}
+}
+
+// Lint inspects the charts and the configuration, returning the findings sorted
+// by location.
+func (l *Linter) Lint() ([]Finding, error) {
+	l.charts = nil
+	l.findings = []Finding{}
+	if err := l.loadProducts(); err != nil {
+		return nil, err
+	}
+	if err := l.loadCharts(); err != nil {
+		return nil, err
+	}
+	cel, err := NewCEL(l.integrationNames...)
+	if err != nil {
+		return nil, err
+	}
+	enabledIf, err := NewCEL()
+	if err != nil {
+		return nil, err
+	}
+	l.lintNames()
+	l.lintAnnotations(cel, enabledIf)
+	l.lintCycles()
+
+	slices.SortStableFunc(l.findings, func(a, b Finding) int {
+		if c := strings.Compare(a.File, b.File); c != 0 {
+			return c
+		}
+		return a.Line - b.Line
+	})
+	return l.findings, nil
+}
+
+// PrintFindings prints the findings to the writer, one per line.
+func PrintFindings(w io.Writer, findings []Finding) {
+	for _, f := range findings {
+		fmt.Fprintln(w, f)
+	}
+}
+
+// PrintFindingsJSON prints the findings to the writer as JSON.
+func PrintFindingsJSON(w io.Writer, findings []Finding) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Findings []Finding `json:"findings"`
+	}{
+		Findings: findings,
+	})
+}
+
+// NewLinter instantiates the Linter for the charts on the filesystem, and the
+// informed configuration file. The appName is the configuration root key, and the
+// integration names are the integrations known by the installer.
+func NewLinter(
+	cfs chartfs.Interface,
+	configPath string,
+	appName string,
+	integrationNames []string,
+) *Linter {
+	return &Linter{
+		cfs:              cfs,
+		configPath:       configPath,
+		appName:          strings.ReplaceAll(appName, "-", "_"),
+		integrationNames: integrationNames,
+	}
+}
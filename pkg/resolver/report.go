@@ -0,0 +1,195 @@
+package resolver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Requirement represents the integrations required by a dependency, the CEL
+// expression and its evaluation result against the configured integrations.
+type Requirement struct {
+	Expression string   `json:"expression" yaml:"expression"`
+	Satisfied  bool     `json:"satisfied" yaml:"satisfied"`
+	Missing    []string `json:"missing,omitempty" yaml:"missing,omitempty"`
+	Error      string   `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Node represents a dependency in the topology report.
+type Node struct {
+	Index                int          `json:"index" yaml:"index"`
+	Level                int          `json:"level" yaml:"level"`
+	Name                 string       `json:"name" yaml:"name"`
+	Namespace            string       `json:"namespace" yaml:"namespace"`
+	Product              string       `json:"product,omitempty" yaml:"product,omitempty"`
+	DependsOn            []string     `json:"dependsOn" yaml:"dependsOn"`
+	SoftDependsOn        []string     `json:"softDependsOn" yaml:"softDependsOn"`
+	Weight               int          `json:"weight" yaml:"weight"`
+	IntegrationsProvided []string     `json:"integrationsProvided" yaml:"integrationsProvided"`
+	IntegrationsRequired *Requirement `json:"integrationsRequired,omitempty" yaml:"integrationsRequired,omitempty"`
+	EnabledIf            string       `json:"enabledIf,omitempty" yaml:"enabledIf,omitempty"`
+}
+
+// Edge represents a "depends-on" relationship between dependencies in the
+// topology, the chart "from" depends on the chart "to". Soft edges represent
+// "soft-depends-on", ordering-only relationships.
+type Edge struct {
+	From string `json:"from" yaml:"from"`
+	To   string `json:"to" yaml:"to"`
+	Soft bool   `json:"soft,omitempty" yaml:"soft,omitempty"`
+}
+
+// Report represents the resolved topology as a directed acyclic graph, the nodes
+// are listed in deployment order.
+type Report struct {
+	Dependencies []Node `json:"dependencies" yaml:"dependencies"`
+	Edges        []Edge `json:"edges" yaml:"edges"`
+}
+
+// PrintJSON prints the report to the writer as JSON.
+func (r *Report) PrintJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// PrintYAML prints the report to the writer as YAML.
+func (r *Report) PrintYAML(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(r); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// label returns the multi-line description of the node, joined by the informed
+// line separator.
+func (n *Node) label(sep string) string {
+	lines := []string{n.Name, "namespace: " + n.Namespace}
+	if n.Product != "" {
+		lines = append(lines, "product: "+n.Product)
+	}
+	if len(n.IntegrationsProvided) > 0 {
+		lines = append(lines,
+			"provides: "+strings.Join(n.IntegrationsProvided, ", "))
+	}
+	if req := n.IntegrationsRequired; req != nil {
+		state := "satisfied"
+		if !req.Satisfied {
+			state = "unsatisfied"
+		}
+		lines = append(lines,
+			fmt.Sprintf("requires: %s (%s)", req.Expression, state))
+	}
+	return strings.Join(lines, sep)
+}
+
+// unsatisfied checks whether the node requirements aren't satisfied.
+func (n *Node) unsatisfied() bool {
+	return n.IntegrationsRequired != nil && !n.IntegrationsRequired.Satisfied
+}
+
+// PrintDOT prints the report to the writer as a Graphviz DOT digraph, the edges
+// point from a chart to the charts it depends on.
+func (r *Report) PrintDOT(w io.Writer) {
+	fmt.Fprintln(w, "digraph topology {")
+	fmt.Fprintln(w, "  node [shape=box];")
+	for _, n := range r.Dependencies {
+		attrs := fmt.Sprintf("label=%q", n.label("\n"))
+		if n.unsatisfied() {
+			attrs += ", color=red"
+		}
+		fmt.Fprintf(w, "  %q [%s];\n", n.Name, attrs)
+	}
+	for _, e := range r.Edges {
+		if e.Soft {
+			fmt.Fprintf(w, "  %q -> %q [style=dashed];\n", e.From, e.To)
+			continue
+		}
+		fmt.Fprintf(w, "  %q -> %q;\n", e.From, e.To)
+	}
+	fmt.Fprintln(w, "}")
+}
+
+// PrintMermaid prints the report to the writer as a Mermaid flowchart, the edges
+// point from a chart to the charts it depends on.
+func (r *Report) PrintMermaid(w io.Writer) {
+	ids := make(map[string]string, len(r.Dependencies))
+	fmt.Fprintln(w, "flowchart TD")
+	for _, n := range r.Dependencies {
+		ids[n.Name] = fmt.Sprintf("n%d", n.Index)
+		label := strings.ReplaceAll(n.label("<br/>"), `"`, "#quot;")
+		fmt.Fprintf(w, "  %s[\"%s\"]\n", ids[n.Name], label)
+	}
+	for _, e := range r.Edges {
+		if e.Soft {
+			fmt.Fprintf(w, "  %s -.-> %s\n", ids[e.From], ids[e.To])
+			continue
+		}
+		fmt.Fprintf(w, "  %s --> %s\n", ids[e.From], ids[e.To])
+	}
+	for _, n := range r.Dependencies {
+		if n.unsatisfied() {
+			fmt.Fprintf(w, "  class %s unsatisfied\n", ids[n.Name])
+		}
+	}
+	fmt.Fprintln(w, "  classDef unsatisfied stroke:#c00,stroke-width:2px")
+}
+
+// NewReport creates the report for the informed topology, the requirements are
+// the evaluated integrations required by each dependency, indexed by name.
+func NewReport(t *Topology, requirements map[string]Requirement) *Report {
+	r := &Report{Dependencies: []Node{}, Edges: []Edge{}}
+	levelOf := map[string]int{}
+	for level, deps := range t.Levels() {
+		for _, d := range deps {
+			levelOf[d.Name()] = level
+		}
+	}
+	for i, d := range t.Dependencies() {
+		weight, _ := d.Weight()
+		n := Node{
+			Index:                i + 1,
+			Level:                levelOf[d.Name()],
+			Name:                 d.Name(),
+			Namespace:            d.Namespace(),
+			Product:              d.ProductName(),
+			DependsOn:            d.DependsOn(),
+			SoftDependsOn:        d.SoftDependsOn(),
+			Weight:               weight,
+			IntegrationsProvided: d.IntegrationsProvided(),
+			EnabledIf:            d.EnabledIf(),
+		}
+		if req, exists := requirements[d.Name()]; exists {
+			n.IntegrationsRequired = &req
+		}
+		if n.DependsOn == nil {
+			n.DependsOn = []string{}
+		}
+		if n.SoftDependsOn == nil {
+			n.SoftDependsOn = []string{}
+		}
+		if n.IntegrationsProvided == nil {
+			n.IntegrationsProvided = []string{}
+		}
+		r.Dependencies = append(r.Dependencies, n)
+		// Only the dependencies part of the topology are linked, the charts of
+		// disabled products are not deployed.
+		for _, dependsOn := range n.DependsOn {
+			if t.Contains(dependsOn) {
+				r.Edges = append(r.Edges, Edge{From: n.Name, To: dependsOn})
+			}
+		}
+		for _, dependsOn := range n.SoftDependsOn {
+			if t.Contains(dependsOn) {
+				r.Edges = append(r.Edges,
+					Edge{From: n.Name, To: dependsOn, Soft: true})
+			}
+		}
+	}
+	return r
+}
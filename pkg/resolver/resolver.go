@@ -0,0 +1,311 @@
+package resolver
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/config"
+)
+
+// Resolver represents the actor that resolves dependencies between charts.
+type Resolver struct {
+	cfg        *config.Config // installer configuration
+	collection *Collection    // collection of charts
+	topology   *Topology      // topology of dependencies
+	vars       *Context       // CEL context variables
+	cel        *CEL           // CEL environment for "enabled-if"
+
+	reasons        map[string][]string // inclusion, or exclusion, reasons
+	namespaceRules map[string]string   // rule choosing the namespace
+}
+
+// because records the reason the chart is, or isn't, part of the topology.
+func (r *Resolver) because(name, format string, a ...any) {
+	reason := fmt.Sprintf(format, a...)
+	if !slices.Contains(r.reasons[name], reason) {
+		r.reasons[name] = append(r.reasons[name], reason)
+	}
+}
+
+// enabled evaluates the chart "enabled-if" expression against the CEL context,
+// charts without the annotation are always enabled.
+func (r *Resolver) enabled(d *Dependency) (bool, error) {
+	expression := d.EnabledIf()
+	if expression == "" {
+		return true, nil
+	}
+	enabled, err := r.cel.Enabled(expression)
+	if err != nil {
+		return false, fmt.Errorf("chart %q enabled-if: %w", d.Name(), err)
+	}
+	return enabled, nil
+}
+
+// ErrCircularDependency reports a circular dependency.
+var ErrCircularDependency = fmt.Errorf("circular dependency detected")
+
+// ErrMissingDependency reports an unmet dependency.
+var ErrMissingDependency = fmt.Errorf("unmet dependency detected")
+
+// setDependencyNamespace sets the desired namespace on the informed dependency.
+// By default, charts are deployed on the same namespace than the installer, while
+// product assossiated dependencies will use the namespace configured for it.
+func (r *Resolver) setDependencyNamespace(d *Dependency) error {
+	var product string
+	// Check if the Helm chart should use the product namespace.
+	if p := d.UseProductNamespace(); p != "" {
+		product = p
+	}
+	// Check if the Helm chart is associated with a product, which takes
+	// precedence over the "use-product-namespace" annotation.
+	if p := d.ProductName(); p != "" {
+		product = p
+	}
+
+	// Choosing the namespace for the dependency, a product chart will use what's
+	// defined for it, while regular charts will use the installer's namespace.
+	var namespace string
+	switch {
+	case product == "":
+		namespace = r.cfg.Namespace()
+		r.namespaceRules[d.Name()] = "installer namespace, the chart is not " +
+			"associated with a product"
+	default:
+		spec, err := r.cfg.GetProduct(product)
+		if err != nil {
+			return err
+		}
+		namespace = *spec.Namespace
+		if d.ProductName() != "" {
+			r.namespaceRules[d.Name()] = fmt.Sprintf(
+				"namespace of the product %q, the chart's product-name", product)
+		} else {
+			r.namespaceRules[d.Name()] = fmt.Sprintf(
+				"namespace of the product %q, the chart's use-product-namespace",
+				product)
+		}
+	}
+	d.SetNamespace(namespace)
+	return nil
+}
+
+// dependsOn checks if the chart has dependencies and resolves them. The
+// dependencies are prepended to the parent chart and when more dependencies are
+// found, they are also resolved.
+func (r *Resolver) dependsOn(
+	parent string, // partent chart name
+	d *Dependency, // dependency instance
+	visited map[string]bool, // visited charts
+) error {
+	// Ensure the chart is not visited again, to prevent circular dependencies.
+	dependencyName := d.Name()
+	if visited[dependencyName] {
+		return fmt.Errorf("%w: a %q dependency requires %q",
+			ErrCircularDependency, dependencyName, dependencyName)
+	}
+	visited[dependencyName] = true
+	defer delete(visited, dependencyName)
+
+	for _, dependsOn := range d.DependsOn() {
+		// Picking up the dependency from the collection by name.
+		dependsOnDep, err := r.collection.Get(dependsOn)
+		if err != nil {
+			return err
+		}
+		// Failing when the next dependency is associated with a disabled
+		// product, ordering-only relationships use "soft-depends-on" instead.
+		if product := dependsOnDep.ProductName(); product != "" {
+			productSpec, err := r.cfg.GetProduct(product)
+			if err != nil {
+				return err
+			}
+			if !productSpec.Enabled {
+				return fmt.Errorf(
+					"%w: %q depends on %q, the chart of the disabled product "+
+						"%q; enable the product, or use soft-depends-on",
+					ErrMissingDependency, parent, dependsOn, product)
+			}
+		}
+		// Failing when the next dependency's "enabled-if" is not satisfied.
+		enabled, err := r.enabled(dependsOnDep)
+		if err != nil {
+			return err
+		}
+		if !enabled {
+			return fmt.Errorf(
+				"%w: %q depends on %q, which enabled-if %q is false; use "+
+					"soft-depends-on for an optional dependency",
+				ErrMissingDependency, parent, dependsOn, dependsOnDep.EnabledIf())
+		}
+		// Setting the correct namespace in the dependency.
+		if err := r.setDependencyNamespace(dependsOnDep); err != nil {
+			return err
+		}
+		// Adding the Helm chart to the topology before the parent chart. The
+		// namespace is the installer's default.
+		r.topology.PrependBefore(parent, *dependsOnDep)
+		r.because(dependsOn, "required by %q (depends-on)", parent)
+		// Recursively resolving the dependencies.
+		if err = r.dependsOn(dependsOn, dependsOnDep, visited); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveEnabledProducts resolves the dependencies of enabled products.
+func (r *Resolver) resolveEnabledProducts() error {
+	for _, product := range r.cfg.GetEnabledProducts() {
+		d, err := r.collection.GetProductDependency(product.Name)
+		if err != nil {
+			return err
+		}
+		// Skipping the product chart when its "enabled-if" is not satisfied.
+		enabled, err := r.enabled(d)
+		if err != nil {
+			return err
+		}
+		if !enabled {
+			continue
+		}
+		// Products uses the namespace specified in the configuration.
+		d.SetNamespace(*product.Namespace)
+		r.namespaceRules[d.Name()] = fmt.Sprintf(
+			"namespace of the product %q, the chart's product-name", product.Name)
+		// Product charts are added to the topology before required charts.
+		r.topology.Append(*d)
+		r.because(d.Name(), "chart of the enabled product %q", product.Name)
+		// Recursively resolving the dependencies, added before this chart.
+		if err = r.dependsOn(d.Name(), d, map[string]bool{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveDependencies final inspection of the Helm charts in the Collection to
+// ensure all dependencies are met. It walks the charts in the Collection, and for
+// each entry verifies it it depends on any chart in the Topology.
+func (r *Resolver) resolveDependencies() error {
+	return r.collection.Walk(func(name string, d Dependency) error {
+		// Skip dependencies that are associated with a product. These have
+		// already been added to the topology.
+		if product := d.ProductName(); product != "" {
+			return nil
+		}
+		// Collecting the last dependency name that is required by the current
+		// chart (dependency), if any. Soft dependencies present in the topology
+		// also attach the chart to it.
+		requiredDependency := ""
+		for _, dependsOn := range append(d.DependsOn(), d.SoftDependsOn()...) {
+			// Ensure the required dependency is in the topology, when not in the
+			// topology it is skipped.
+			if !r.topology.Contains(dependsOn) {
+				continue
+			}
+			// Ensures the required dependency is in the collection.
+			if _, err := r.collection.Get(dependsOn); err != nil {
+				return fmt.Errorf(
+					"%w: dependency %s not found for chart %s",
+					ErrMissingDependency,
+					dependsOn,
+					name,
+				)
+			}
+			requiredDependency = dependsOn
+		}
+		// If there is no required dependency, skip it.
+		if requiredDependency == "" {
+			return nil
+		}
+		// Skipping the chart when its "enabled-if" is not satisfied.
+		enabled, err := r.enabled(&d)
+		if err != nil {
+			return err
+		}
+		if !enabled {
+			return nil
+		}
+		// Setting the desired namespace in the dependency.
+		if err := r.setDependencyNamespace(&d); err != nil {
+			return err
+		}
+		// Append the current dependency after the last one in the collection that
+		// requires it.
+		r.topology.AppendAfter(requiredDependency, d)
+		if slices.Contains(d.DependsOn(), requiredDependency) {
+			r.because(name, "depends on %q, which is part of the topology",
+				requiredDependency)
+		} else {
+			r.because(name, "soft-depends on %q, which is part of the topology",
+				requiredDependency)
+		}
+		// Recursively resolve dependencies.
+		return r.dependsOn(name, &d, map[string]bool{})
+	})
+}
+
+// Resolve resolves the all dependencies in the collection to create the topology.
+func (r *Resolver) Resolve() error {
+	var err error
+	if r.cel, err = NewCEL(); err != nil {
+		return err
+	}
+	r.cel.SetContext(r.vars)
+	if err = r.resolveEnabledProducts(); err != nil {
+		return err
+	}
+	if err = r.resolveDependencies(); err != nil {
+		return err
+	}
+	// Honoring the soft dependencies, now all charts in the topology are known.
+	return r.topology.order()
+}
+
+// Print prints the resolved topology to the writer formatted as a table.
+func (r *Resolver) Print(w io.Writer) {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	row := func(a ...any) {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", a...)
+	}
+	row("Index", "Dependency", "Namespace", "Product", "Depends-On",
+		"Soft-Depends-On", "Weight", "Provided-Integrations",
+		"Required-Integrations")
+	for i, d := range r.topology.Dependencies() {
+		weight, _ := d.Weight()
+		row(
+			fmt.Sprintf("%2d", i+1),
+			d.Name(),
+			d.Namespace(),
+			d.ProductName(),
+			strings.Join(d.DependsOn(), ", "),
+			strings.Join(d.SoftDependsOn(), ", "),
+			fmt.Sprintf("%d", weight),
+			strings.Join(d.IntegrationsProvided(), ", "),
+			d.IntegrationsRequired(),
+		)
+	}
+	table.Flush()
+}
+
+// NewResolver instantiates a new Resolver. It takes the configuration, collection
+// and topology as parameters, and the CEL context variables for the charts
+// "enabled-if" expressions.
+func NewResolver(
+	cfg *config.Config,
+	c *Collection,
+	t *Topology,
+	vars *Context,
+) *Resolver {
+	return &Resolver{
+		cfg:            cfg,
+		collection:     c,
+		topology:       t,
+		vars:           vars,
+		reasons:        map[string][]string{},
+		namespaceRules: map[string]string{},
+	}
+}
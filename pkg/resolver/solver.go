@@ -0,0 +1,403 @@
+package resolver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/config"
+)
+
+// Solution represents a minimal set of integrations which, once configured,
+// satisfy the integrations required by all dependencies in the topology.
+type Solution struct {
+	// Integrations the missing integrations to configure, sorted by name.
+	Integrations []string `json:"integrations"`
+	// Providers the products which provide the integration when enabled,
+	// indexed by integration name.
+	Providers map[string][]string `json:"providers,omitempty"`
+}
+
+// manual returns the number of integrations in the solution which no product
+// provides, thus must be configured manually.
+func (s *Solution) manual() int {
+	count := 0
+	for _, name := range s.Integrations {
+		if len(s.Providers[name]) == 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// Unsatisfied represents a dependency which integrations requirement isn't
+// satisfied by the configured integrations.
+type Unsatisfied struct {
+	Chart      string   `json:"chart"`      // dependency name
+	Expression string   `json:"expression"` // CEL expression
+	Missing    []string `json:"missing"`    // referenced missing integrations
+}
+
+// Solutions represents the outcome of solving the topology requirements, the
+// unsatisfied dependencies and the alternative sets of integrations, in order of
+// preference.
+type Solutions struct {
+	Unsatisfied  []Unsatisfied `json:"unsatisfied"`
+	Alternatives []Solution    `json:"alternatives"`
+}
+
+// PrintJSON prints the solutions to the writer as JSON.
+func (s *Solutions) PrintJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// PrintText prints the solutions to the writer, human readable. The scaffold
+// function returns the command to configure the informed integration.
+func (s *Solutions) PrintText(w io.Writer, scaffold func(string) string) {
+	if len(s.Unsatisfied) == 0 {
+		fmt.Fprintf(w, "All required integrations are satisfied.\n")
+		return
+	}
+	fmt.Fprintf(w, "Unsatisfied integration requirements:\n\n")
+	for _, u := range s.Unsatisfied {
+		fmt.Fprintf(w, "  - %s: %s\n", u.Chart, u.Expression)
+	}
+	fmt.Fprintln(w)
+	s.PrintAlternatives(w, scaffold)
+}
+
+// PrintAlternatives prints the alternative sets of integrations to the writer,
+// human readable. The scaffold function returns the command to configure the
+// informed integration.
+func (s *Solutions) PrintAlternatives(w io.Writer, scaffold func(string) string) {
+	if len(s.Alternatives) == 0 {
+		fmt.Fprintf(w, "No set of integrations satisfies the requirements.\n")
+		return
+	}
+	fmt.Fprintf(w, "Integrations to configure, alternatives in order of "+
+		"preference:\n")
+	for i, a := range s.Alternatives {
+		fmt.Fprintf(w, "\n%2d. %s\n", i+1, strings.Join(a.Integrations, ", "))
+		for _, name := range a.Integrations {
+			if products := a.Providers[name]; len(products) > 0 {
+				fmt.Fprintf(w, "    # %s: provided by enabling the product %s\n",
+					name, strings.Join(products, ", "))
+				continue
+			}
+			fmt.Fprintf(w, "    %s\n", scaffold(name))
+		}
+	}
+}
+
+// satisfied checks whether all the requirements are satisfied by the informed
+// configured integrations.
+func (i *Integrations) satisfied(
+	configured map[string]bool,
+	required map[string]string,
+) (bool, error) {
+	for _, expression := range required {
+		ok, _, err := i.cel.Result(configured, expression)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// providers returns the enabled-on-demand providers of each integration, the
+// charts of disabled products providing it, indexed by integration name.
+func providers(t *Topology, c *Collection) map[string][]string {
+	p := map[string][]string{}
+	if c == nil {
+		return p
+	}
+	_ = c.Walk(func(name string, d Dependency) error {
+		product := d.ProductName()
+		if product == "" || t.Contains(name) {
+			return nil
+		}
+		for _, provided := range d.IntegrationsProvided() {
+			if !slices.Contains(p[provided], product) {
+				p[provided] = append(p[provided], product)
+			}
+		}
+		return nil
+	})
+	return p
+}
+
+// ProviderVerifier verifies a product proposed by the solver to provide an
+// integration, once enabled. The configured integrations are the ones configured
+// in the cluster, plus the ones of the solution.
+type ProviderVerifier func(product string, configured map[string]bool) error
+
+// SetProviderVerifier sets the verification of the products proposed by Solve to
+// provide the missing integrations, the products failing it aren't proposed.
+func (i *Integrations) SetProviderVerifier(v ProviderVerifier) {
+	i.verifier = v
+}
+
+// NewProviderVerifier returns the ProviderVerifier which resolves the topology
+// of the configuration with the product enabled, as the deployment would, and
+// verifies the integrations required by the resulting dependencies, and the
+// cluster capabilities, when informed. The results are cached.
+func NewProviderVerifier(
+	cfg *config.Config,
+	c *Collection,
+	vars *Context,
+	caps *Capabilities,
+) ProviderVerifier {
+	results := map[string]error{}
+	return func(product string, configured map[string]bool) error {
+		names := slices.Sorted(maps.Keys(configured))
+		key := product
+		for _, name := range names {
+			if configured[name] {
+				key += "," + name
+			}
+		}
+		if err, cached := results[key]; cached {
+			return err
+		}
+		err := verifyProvider(cfg, c, vars, caps, product, configured, names)
+		results[key] = err
+		return err
+	}
+}
+
+// verifyProvider enables the product on a copy of the configuration, resolves
+// the topology and verifies its integrations and capabilities requirements.
+func verifyProvider(
+	cfg *config.Config,
+	c *Collection,
+	vars *Context,
+	caps *Capabilities,
+	product string,
+	configured map[string]bool,
+	integrationNames []string,
+) error {
+	enabled, err := cfg.Clone()
+	if err != nil {
+		return err
+	}
+	spec, err := enabled.GetProduct(product)
+	if err != nil {
+		return err
+	}
+	spec.Enabled = true
+	if err = enabled.SetProduct(product, *spec); err != nil {
+		return err
+	}
+	// The context variables describe the configuration with the product enabled.
+	productVars := *NewContext(enabled, "")
+	if vars != nil {
+		productVars.OpenShift = vars.OpenShift
+		productVars.Cluster = vars.Cluster
+	}
+
+	t := NewTopology()
+	if err = NewResolver(enabled, c, t, &productVars).Resolve(); err != nil {
+		return err
+	}
+	cel, err := NewCEL(integrationNames...)
+	if err != nil {
+		return err
+	}
+	cel.SetContext(&productVars)
+	i := &Integrations{configured: maps.Clone(configured), cel: cel}
+	if err = i.Inspect(t); err != nil {
+		return err
+	}
+	if caps == nil {
+		return nil
+	}
+	return caps.Verify(t, &productVars)
+}
+
+// maxSolverCombinations the maximum number of combinations of candidate
+// integrations evaluated while solving the requirements.
+const maxSolverCombinations = 1 << 12
+
+// ErrTooManyCandidates when the requirements reference too many missing
+// integrations to search for the minimal sets.
+var ErrTooManyCandidates = errors.New("too many candidate integrations")
+
+// search finds the minimal sets of candidates, as candidate indexes, which
+// satisfy all the requirements. The combinations are inspected by increasing
+// size, combinations containing a smaller solution aren't minimal and are
+// skipped. The search is bounded by maxSolverCombinations.
+func (i *Integrations) search(
+	candidates []string,
+	required map[string]string,
+) ([][]int, error) {
+	found := [][]int{}
+	evaluated := 0
+	for size := 1; size <= len(candidates); size++ {
+		set := make([]int, size)
+		for j := range set {
+			set[j] = j
+		}
+		for {
+			if !slices.ContainsFunc(found, func(f []int) bool {
+				return subset(f, set)
+			}) {
+				if evaluated++; evaluated > maxSolverCombinations {
+					return nil, fmt.Errorf(
+						"%w: %d missing integrations referenced (%s), the "+
+							"search is limited to %d combinations",
+						ErrTooManyCandidates,
+						len(candidates),
+						strings.Join(candidates, ", "),
+						maxSolverCombinations,
+					)
+				}
+				configured := maps.Clone(i.configured)
+				for _, index := range set {
+					configured[candidates[index]] = true
+				}
+				ok, err := i.satisfied(configured, required)
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					found = append(found, slices.Clone(set))
+				}
+			}
+			if !nextCombination(set, len(candidates)) {
+				break
+			}
+		}
+	}
+	return found, nil
+}
+
+// nextCombination advances the set of sorted indexes to the next combination of
+// the same size, in lexicographic order, out of n. Returns false when the set is
+// the last combination.
+func nextCombination(set []int, n int) bool {
+	k := len(set)
+	for j := k - 1; j >= 0; j-- {
+		if set[j] < n-k+j {
+			set[j]++
+			for l := j + 1; l < k; l++ {
+				set[l] = set[l-1] + 1
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// subset checks whether all elements of the sorted set a are in the sorted set b.
+func subset(a, b []int) bool {
+	j := 0
+	for _, v := range a {
+		for j < len(b) && b[j] < v {
+			j++
+		}
+		if j == len(b) || b[j] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Solve evaluates the combined integrations requirements of the topology, and
+// when unsatisfied, finds the minimal alternative sets of missing integrations
+// which satisfy all of them. The alternatives are ranked by the number of
+// integrations to configure manually, not provided by enabling a product of the
+// collection, and then by size. The providing products are verified beforehand,
+// when a ProviderVerifier is set.
+func (i *Integrations) Solve(t *Topology, c *Collection) (*Solutions, error) {
+	if err := i.provide(t); err != nil {
+		return nil, err
+	}
+	s := &Solutions{Unsatisfied: []Unsatisfied{}, Alternatives: []Solution{}}
+	required := map[string]string{}
+	candidates := []string{}
+	err := t.Walk(func(chartName string, d Dependency) error {
+		expression := d.IntegrationsRequired()
+		if expression == "" {
+			return nil
+		}
+		required[chartName] = expression
+		ok, missing, err := i.cel.Result(i.configured, expression)
+		if err != nil {
+			return fmt.Errorf("dependency %q: %w", chartName, err)
+		}
+		if !ok {
+			s.Unsatisfied = append(s.Unsatisfied, Unsatisfied{
+				Chart:      chartName,
+				Expression: expression,
+				Missing:    missing,
+			})
+		}
+		// The candidates are all referenced integrations not yet configured.
+		references, err := i.cel.References(expression)
+		if err != nil {
+			return err
+		}
+		for _, ref := range references {
+			if configured, known := i.configured[ref]; known && !configured &&
+				!slices.Contains(candidates, ref) {
+				candidates = append(candidates, ref)
+			}
+		}
+		return nil
+	})
+	if err != nil || len(s.Unsatisfied) == 0 {
+		return s, err
+	}
+	slices.Sort(candidates)
+
+	found, err := i.search(candidates, required)
+	if err != nil {
+		return nil, err
+	}
+
+	p := providers(t, c)
+	for _, set := range found {
+		solution := Solution{Integrations: []string{}}
+		configured := maps.Clone(i.configured)
+		for _, index := range set {
+			configured[candidates[index]] = true
+		}
+		for _, index := range set {
+			name := candidates[index]
+			solution.Integrations = append(solution.Integrations, name)
+			// Only the products which resolve and satisfy their own requirements,
+			// once enabled, are proposed as providers.
+			products := []string{}
+			for _, product := range p[name] {
+				if i.verifier != nil && i.verifier(product, configured) != nil {
+					continue
+				}
+				products = append(products, product)
+			}
+			if len(products) > 0 {
+				if solution.Providers == nil {
+					solution.Providers = map[string][]string{}
+				}
+				solution.Providers[name] = products
+			}
+		}
+		s.Alternatives = append(s.Alternatives, solution)
+	}
+	slices.SortStableFunc(s.Alternatives, func(a, b Solution) int {
+		if d := a.manual() - b.manual(); d != 0 {
+			return d
+		}
+		if d := len(a.Integrations) - len(b.Integrations); d != 0 {
+			return d
+		}
+		return strings.Compare(
+			strings.Join(a.Integrations, ","), strings.Join(b.Integrations, ","))
+	})
+	return s, nil
+}
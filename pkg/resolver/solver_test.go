@@ -0,0 +1,239 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/annotations"
+	"github.com/redhat-appstudio/tssc-cli/pkg/config"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// newTestDependency creates a dependency for a chart with the informed
+// annotations.
+func newTestDependency(name string, annotations map[string]string) Dependency {
+	return *NewDependencyWithNamespace(&chart.Chart{
+		Metadata: &chart.Metadata{
+			Name:        name,
+			Version:     "1.0.0",
+			Annotations: annotations,
+		},
+	}, "test")
+}
+
+// newTestIntegrations creates the integrations with the informed names, the
+// configured ones marked as such.
+func newTestIntegrations(
+	t *testing.T,
+	names []string,
+	configured ...string,
+) *Integrations {
+	t.Helper()
+	cel, err := NewCEL(names...)
+	if err != nil {
+		t.Fatalf("creating CEL environment: %v", err)
+	}
+	i := &Integrations{configured: map[string]bool{}, cel: cel}
+	for _, name := range names {
+		i.configured[name] = slices.Contains(configured, name)
+	}
+	return i
+}
+
+func TestIntegrationsSolve(t *testing.T) {
+	names := []string{"acs", "github", "gitlab", "quay", "artifactory"}
+
+	tests := []struct {
+		name         string
+		configured   []string
+		required     []string
+		unsatisfied  int
+		alternatives [][]string
+	}{{
+		name:         "satisfied requirements",
+		configured:   []string{"github"},
+		required:     []string{"github || gitlab"},
+		alternatives: [][]string{},
+	}, {
+		name:         "single missing integration",
+		required:     []string{"acs"},
+		unsatisfied:  1,
+		alternatives: [][]string{{"acs"}},
+	}, {
+		name:         "alternatives ranked by size and name",
+		required:     []string{"github || (gitlab && quay)"},
+		unsatisfied:  1,
+		alternatives: [][]string{{"github"}, {"gitlab", "quay"}},
+	}, {
+		name: "combined requirements of multiple dependencies",
+		required: []string{
+			"github || gitlab",
+			"quay || artifactory",
+		},
+		unsatisfied: 2,
+		alternatives: [][]string{
+			{"artifactory", "github"},
+			{"artifactory", "gitlab"},
+			{"github", "quay"},
+			{"gitlab", "quay"},
+		},
+	}, {
+		name:         "configured integrations are not candidates",
+		configured:   []string{"quay"},
+		required:     []string{"(github || gitlab) && quay"},
+		unsatisfied:  1,
+		alternatives: [][]string{{"github"}, {"gitlab"}},
+	}, {
+		name:        "supersets of a solution are not minimal",
+		required:    []string{"github || (github && gitlab) || (gitlab && acs)"},
+		unsatisfied: 1,
+		alternatives: [][]string{
+			{"github"},
+			{"acs", "gitlab"},
+		},
+	}, {
+		name:         "unsatisfiable requirements",
+		required:     []string{"github && !github"},
+		unsatisfied:  1,
+		alternatives: [][]string{},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topology := NewTopology()
+			for n, expression := range tt.required {
+				topology.Append(newTestDependency(
+					fmt.Sprintf("chart-%d", n),
+					map[string]string{annotations.IntegrationsRequired: expression},
+				))
+			}
+			i := newTestIntegrations(t, names, tt.configured...)
+			s, err := i.Solve(topology, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(s.Unsatisfied) != tt.unsatisfied {
+				t.Errorf("expected %d unsatisfied, got %v",
+					tt.unsatisfied, s.Unsatisfied)
+			}
+			alternatives := [][]string{}
+			for _, a := range s.Alternatives {
+				alternatives = append(alternatives, a.Integrations)
+			}
+			if fmt.Sprint(alternatives) != fmt.Sprint(tt.alternatives) {
+				t.Errorf("expected alternatives %v, got %v",
+					tt.alternatives, alternatives)
+			}
+		})
+	}
+}
+
+func TestIntegrationsSolveVerifiedProviders(t *testing.T) {
+	// The "Registry" product provides "quay", but its chart requires "github",
+	// thus enabling it alone doesn't satisfy the topology.
+	product := func(name, product string, a map[string]string) chart.Chart {
+		a[annotations.ProductName] = product
+		return chart.Chart{Metadata: &chart.Metadata{
+			Name:        name,
+			Version:     "1.0.0",
+			Annotations: a,
+		}}
+	}
+	collection, err := NewCollection(nil, []chart.Chart{
+		product("app", "App", map[string]string{
+			annotations.IntegrationsRequired: "quay || artifactory",
+		}),
+		product("registry", "Registry", map[string]string{
+			annotations.IntegrationsProvided: "quay",
+			annotations.IntegrationsRequired: "github",
+		}),
+	})
+	if err != nil {
+		t.Fatalf("creating collection: %v", err)
+	}
+	cfg, err := config.NewConfigFromBytes([]byte(`---
+tssc:
+  settings: {}
+  products:
+    - name: App
+      enabled: true
+      namespace: app
+    - name: Registry
+      enabled: false
+      namespace: registry
+`), "tssc", "tssc")
+	if err != nil {
+		t.Fatalf("loading configuration: %v", err)
+	}
+	topology := NewTopology()
+	if err = NewResolver(cfg, collection, topology, nil).Resolve(); err != nil {
+		t.Fatalf("resolving topology: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		verify       bool
+		alternatives string
+	}{{
+		name:         "providers not verified",
+		alternatives: "[{[quay] map[quay:[Registry]]} {[artifactory] map[]}]",
+	}, {
+		name:         "providers verified",
+		verify:       true,
+		alternatives: "[{[artifactory] map[]} {[quay] map[]}]",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := newTestIntegrations(t, []string{"artifactory", "github", "quay"})
+			if tt.verify {
+				i.SetProviderVerifier(
+					NewProviderVerifier(cfg, collection, nil, nil))
+			}
+			s, err := i.Solve(topology, collection)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := fmt.Sprint(s.Alternatives); got != tt.alternatives {
+				t.Errorf("expected alternatives %s, got %s",
+					tt.alternatives, got)
+			}
+		})
+	}
+}
+
+func TestIntegrationsSolveTooManyCandidates(t *testing.T) {
+	// The requirement needs all the missing integrations, thus no small set is a
+	// solution and the search can't stop early.
+	names := []string{}
+	terms := []string{}
+	for n := 0; n < 16; n += 2 {
+		a, b := fmt.Sprintf("i%02d", n), fmt.Sprintf("i%02d", n+1)
+		names = append(names, a, b)
+		terms = append(terms, fmt.Sprintf("(%s && %s)", a, b))
+	}
+	topology := NewTopology()
+	topology.Append(newTestDependency("chart", map[string]string{
+		annotations.IntegrationsRequired: strings.Join(terms, " && "),
+	}))
+	_, err := newTestIntegrations(t, names).Solve(topology, nil)
+	if !errors.Is(err, ErrTooManyCandidates) {
+		t.Fatalf("expected error %v, got %v", ErrTooManyCandidates, err)
+	}
+}
+
+func TestNextCombination(t *testing.T) {
+	set := []int{0, 1}
+	combinations := []string{fmt.Sprint(set)}
+	for nextCombination(set, 4) {
+		combinations = append(combinations, fmt.Sprint(set))
+	}
+	expected := "[[0 1] [0 2] [0 3] [1 2] [1 3] [2 3]]"
+	if got := fmt.Sprint(combinations); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
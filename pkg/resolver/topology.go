@@ -0,0 +1,255 @@
+package resolver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Topology represents the dependency topology, determines the order in which
+// charts (dependencies) will be installed.
+type Topology struct {
+	dependencies Dependencies // dependency topology
+}
+
+// Dependencies exposes the list of dependencies.
+func (t *Topology) Dependencies() Dependencies {
+	return t.dependencies
+}
+
+// GetDependency returns the dependency for a given dependency name.
+func (t *Topology) GetDependency(name string) (*Dependency, error) {
+	for i := range t.dependencies {
+		if t.dependencies[i].Name() == name {
+			return &t.dependencies[i], nil
+		}
+	}
+	return nil, fmt.Errorf("dependency %q not found", name)
+}
+
+// Contains checks if a dependency Contains in the topology.
+func (t *Topology) Contains(name string) bool {
+	for _, d := range t.dependencies {
+		if d.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Walk traverses the topology and calls the informed function for each
+// dependency.
+func (t *Topology) Walk(fn DependencyWalkFn) error {
+	for i := range t.dependencies {
+		if err := fn(t.dependencies[i].Name(), t.dependencies[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dependencyIndex given the dependency name, find the its index in the topology,
+// or returns -1 if not found.
+func (t *Topology) dependencyIndex(name string) int {
+	for i, d := range t.dependencies {
+		if d.Name() == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// except returns a list of dependencies that are not in the topology.
+func (t *Topology) except(dependencies ...Dependency) Dependencies {
+	except := Dependencies{}
+	for _, dependency := range dependencies {
+		if !t.Contains(dependency.Name()) {
+			except = append(except, dependency)
+		}
+	}
+	return except
+}
+
+// PrependBefore prepends a list of dependencies before a specific dependency,
+// taking the weight into account.
+func (t *Topology) PrependBefore(name string, dependencies ...Dependency) {
+	except := t.except(dependencies...)
+	if len(except) == 0 {
+		return
+	}
+
+	// Find the index where the dependency name exists.
+	dependencyIndex := t.dependencyIndex(name)
+
+	// The dependency is not found, prepend to the very beginning of the slice.
+	if dependencyIndex == -1 {
+		t.dependencies = append(except, t.dependencies...)
+		return
+	}
+
+	insertIndex := dependencyIndex
+
+	// Calculate the insert index based on weights.
+	for _, dep := range except {
+		pos := insertIndex
+		currentWeight, _ := dep.Weight()
+
+		for pos > 0 {
+			prevWeight, _ := t.dependencies[pos-1].Weight()
+			if currentWeight >= prevWeight {
+				break
+			}
+			pos--
+		}
+
+		t.dependencies = append(
+			t.dependencies[:pos],
+			append([]Dependency{dep}, t.dependencies[pos:]...)...,
+		)
+
+		dependencyIndex++
+		insertIndex = dependencyIndex
+	}
+}
+
+// AppendAfter inserts dependencies after a given dependency name. If the
+// dependency does not exist, it appends to the end the slice.
+func (t *Topology) AppendAfter(name string, dependencies ...Dependency) {
+	except := t.except(dependencies...)
+	if len(except) == 0 {
+		return
+	}
+
+	// Find the index where the dependency name exists.
+	dependencyIndex := t.dependencyIndex(name)
+
+	if dependencyIndex == -1 {
+		t.dependencies = append(t.dependencies, except...)
+		return
+	}
+
+	// The insert index starts right next to the dependency name.
+	insertIndex := dependencyIndex + 1
+
+	// Calculate the insert index based on weights.
+	for _, dep := range except {
+		pos := insertIndex
+		currentWeight, _ := dep.Weight()
+
+		for pos < len(t.dependencies) {
+			nextWeight, _ := t.dependencies[pos].Weight()
+			if currentWeight <= nextWeight {
+				break
+			}
+			pos++
+		}
+
+		t.dependencies = append(
+			t.dependencies[:pos],
+			append([]Dependency{dep}, t.dependencies[pos:]...)...,
+		)
+	}
+}
+
+// Append adds a new dependency to the end of the topology.
+func (t *Topology) Append(d Dependency) {
+	if t.Contains(d.Name()) {
+		return
+	}
+	t.dependencies = append(t.dependencies, d)
+}
+
+// predecessors returns the names of the dependencies in the topology the informed
+// dependency must be deployed after, the charts it depends on, and the soft
+// dependencies present in the topology.
+func (t *Topology) predecessors(d Dependency) []string {
+	names := []string{}
+	for _, name := range append(d.DependsOn(), d.SoftDependsOn()...) {
+		if name != d.Name() && t.Contains(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// order sorts the topology ensuring every dependency comes after its
+// predecessors, the soft dependencies are only honored here. The current order
+// is kept whenever possible, a dependency is only moved to follow its
+// predecessors.
+func (t *Topology) order() error {
+	placed := make(map[string]bool, len(t.dependencies))
+	sorted := make(Dependencies, 0, len(t.dependencies))
+	for len(sorted) < len(t.dependencies) {
+		next := -1
+		for i, d := range t.dependencies {
+			if placed[d.Name()] {
+				continue
+			}
+			ready := true
+			for _, name := range t.predecessors(d) {
+				if !placed[name] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				next = i
+				break
+			}
+		}
+		// No dependency left can be placed, the remaining form a cycle.
+		if next == -1 {
+			remaining := []string{}
+			for _, d := range t.dependencies {
+				if !placed[d.Name()] {
+					remaining = append(remaining, d.Name())
+				}
+			}
+			return fmt.Errorf("%w: between %s", ErrCircularDependency,
+				strings.Join(remaining, ", "))
+		}
+		placed[t.dependencies[next].Name()] = true
+		sorted = append(sorted, t.dependencies[next])
+	}
+	t.dependencies = sorted
+	return nil
+}
+
+// Levels groups the dependencies in deployment levels, the dependencies of a
+// level only depend on dependencies of previous levels, thus they can be deployed
+// concurrently. A dependency is placed after the dependencies it depends on,
+// including soft dependencies, and after the preceding dependencies with lower
+// weight. Within a level, the topology order is kept.
+func (t *Topology) Levels() []Dependencies {
+	levelOf := make(map[string]int, len(t.dependencies))
+	levels := []Dependencies{}
+	for i, d := range t.dependencies {
+		level := 0
+		for _, dependsOn := range t.predecessors(d) {
+			if l, exists := levelOf[dependsOn]; exists && l >= level {
+				level = l + 1
+			}
+		}
+		// The weight fine-tunes the order of dependencies without direct
+		// relationship, thus it's also honored between levels.
+		weight, _ := d.Weight()
+		for _, previous := range t.dependencies[:i] {
+			w, _ := previous.Weight()
+			if l := levelOf[previous.Name()]; w < weight && l >= level {
+				level = l + 1
+			}
+		}
+		levelOf[d.Name()] = level
+		for len(levels) <= level {
+			levels = append(levels, Dependencies{})
+		}
+		levels[level] = append(levels[level], d)
+	}
+	return levels
+}
+
+// NewTopology creates a new topology instance.
+func NewTopology() *Topology {
+	return &Topology{
+		dependencies: Dependencies{},
+	}
+}
@@ -0,0 +1,199 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/annotations"
+)
+
+// testChart describes a chart of the test topology, by name and annotations.
+type testChart struct {
+	name        string
+	annotations map[string]string
+}
+
+// newTestTopology creates a topology with the charts, in the informed order.
+func newTestTopology(charts ...testChart) *Topology {
+	t := NewTopology()
+	for _, c := range charts {
+		t.Append(newTestDependency(c.name, c.annotations))
+	}
+	return t
+}
+
+// names returns the names of the dependencies, in order.
+func names(deps Dependencies) []string {
+	result := make([]string, 0, len(deps))
+	for _, d := range deps {
+		result = append(result, d.Name())
+	}
+	return result
+}
+
+func TestTopologyLevels(t *testing.T) {
+	dependsOn := func(names string) map[string]string {
+		return map[string]string{annotations.DependsOn: names}
+	}
+
+	tests := []struct {
+		name   string
+		charts []testChart
+		levels string
+	}{{
+		name:   "independent charts",
+		charts: []testChart{{name: "a"}, {name: "b"}, {name: "c"}},
+		levels: "[[a b c]]",
+	}, {
+		name: "chain",
+		charts: []testChart{
+			{name: "a"},
+			{name: "b", annotations: dependsOn("a")},
+			{name: "c", annotations: dependsOn("b")},
+		},
+		levels: "[[a] [b] [c]]",
+	}, {
+		name: "diamond",
+		charts: []testChart{
+			{name: "a"},
+			{name: "b", annotations: dependsOn("a")},
+			{name: "c", annotations: dependsOn("a")},
+			{name: "d", annotations: dependsOn("b, c")},
+		},
+		levels: "[[a] [b c] [d]]",
+	}, {
+		name: "placed after the deepest dependency",
+		charts: []testChart{
+			{name: "a"},
+			{name: "b", annotations: dependsOn("a")},
+			{name: "c", annotations: dependsOn("a, b")},
+			{name: "d"},
+		},
+		levels: "[[a d] [b] [c]]",
+	}, {
+		name: "version constraints are ignored",
+		charts: []testChart{
+			{name: "a"},
+			{name: "b", annotations: dependsOn("a >=1.0.0")},
+		},
+		levels: "[[a] [b]]",
+	}, {
+		name: "heavier charts come after the preceding lighter ones",
+		charts: []testChart{
+			{name: "a"},
+			{name: "b", annotations: map[string]string{annotations.Weight: "10"}},
+			{name: "c"},
+		},
+		levels: "[[a c] [b]]",
+	}, {
+		name: "soft dependencies present in the topology",
+		charts: []testChart{
+			{name: "a"},
+			{name: "b", annotations: map[string]string{
+				annotations.SoftDependsOn: "a, missing",
+			}},
+		},
+		levels: "[[a] [b]]",
+	}, {
+		name: "soft dependencies absent from the topology",
+		charts: []testChart{
+			{name: "a"},
+			{name: "b", annotations: map[string]string{
+				annotations.SoftDependsOn: "missing",
+			}},
+		},
+		levels: "[[a b]]",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			levels := []string{}
+			for _, level := range newTestTopology(tt.charts...).Levels() {
+				levels = append(levels, fmt.Sprint(names(level)))
+			}
+			if got := fmt.Sprint(levels); got != tt.levels {
+				t.Errorf("expected levels %s, got %s", tt.levels, got)
+			}
+		})
+	}
+}
+
+func TestTopologyOrderSoftDependencies(t *testing.T) {
+	softDependsOn := func(names string) map[string]string {
+		return map[string]string{annotations.SoftDependsOn: names}
+	}
+
+	tests := []struct {
+		name   string
+		charts []testChart
+		order  string
+		err    error
+	}{{
+		name: "moved after the soft dependency",
+		charts: []testChart{
+			{name: "b", annotations: softDependsOn("a")},
+			{name: "a"},
+		},
+		order: "[a b]",
+	}, {
+		name: "soft dependency absent from the topology",
+		charts: []testChart{
+			{name: "b", annotations: softDependsOn("missing")},
+			{name: "a"},
+		},
+		order: "[b a]",
+	}, {
+		name: "self reference is ignored",
+		charts: []testChart{
+			{name: "a", annotations: softDependsOn("a")},
+			{name: "b"},
+		},
+		order: "[a b]",
+	}, {
+		name: "combined with hard dependencies",
+		charts: []testChart{
+			{name: "c", annotations: softDependsOn("b")},
+			{name: "b", annotations: map[string]string{
+				annotations.DependsOn: "a",
+			}},
+			{name: "a"},
+		},
+		order: "[a b c]",
+	}, {
+		name: "the order is kept when possible",
+		charts: []testChart{
+			{name: "x"},
+			{name: "b", annotations: softDependsOn("a")},
+			{name: "y"},
+			{name: "a"},
+		},
+		order: "[x y a b]",
+	}, {
+		name: "circular soft dependencies",
+		charts: []testChart{
+			{name: "a", annotations: softDependsOn("b")},
+			{name: "b", annotations: softDependsOn("a")},
+		},
+		err: ErrCircularDependency,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topology := newTestTopology(tt.charts...)
+			err := topology.order()
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected error %v, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := fmt.Sprint(names(topology.Dependencies())); got != tt.order {
+				t.Errorf("expected order %s, got %s", tt.order, got)
+			}
+		})
+	}
+}
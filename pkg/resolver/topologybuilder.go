@@ -0,0 +1,145 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/chartfs"
+	"github.com/redhat-appstudio/tssc-cli/pkg/config"
+	"github.com/redhat-appstudio/tssc-cli/pkg/k8s"
+
+	"github.com/redhat-appstudio/helmet/api"
+)
+
+// TopologyBuilder represents the complete workflow to generate a consolidated
+// Topology, with dependencies and integrations verified.
+type TopologyBuilder struct {
+	appCtx           *api.AppContext // application context
+	logger           *slog.Logger    // application logger
+	kube             k8s.Interface   // kubernetes client
+	collection       *Collection     // charts collection
+	integrationNames []string        // known integration names
+}
+
+// GetCollection exposes the collection instance.
+func (t *TopologyBuilder) GetCollection() *Collection {
+	return t.collection
+}
+
+// Build inspects the dependencies, based on the cluster configuration, inspects
+// the integrations and the cluster capabilities, and generates a consolidated
+// Topology.
+func (t *TopologyBuilder) Build(
+	ctx context.Context,
+	cfg *config.Config,
+) (*Topology, error) {
+	topology := NewTopology()
+	vars := NewContextFromCluster(ctx, t.kube, cfg)
+	r := NewResolver(cfg, t.collection, topology, vars)
+
+	// Inspecting all charts, dependencies, to organize the topology, which is the
+	// sequence of dependencies deployment.
+	t.logger.Debug("Resolving the topology dependencies...")
+	err := r.Resolve()
+	if err != nil {
+		return nil, err
+	}
+	// Given the Topology is created, now the integrations are verified to ensure
+	// all required integrations secrets are configured.
+	t.logger.Debug("Inspecting integrations...")
+	i, err := NewIntegrations(
+		ctx, t.kube, cfg, vars, t.appCtx.Name, t.integrationNames)
+	if err != nil {
+		return nil, err
+	}
+	t.logger.Debug("Asserting all required integrations are configured...")
+	if err = i.Inspect(topology); err != nil {
+		if errors.Is(err, ErrPrerequisiteIntegration) {
+			return nil, t.alternativesHint(err, cfg, vars, i, topology)
+		}
+		return nil, err
+	}
+	// Before any chart is installed, the cluster must offer the APIs and
+	// features the charts require.
+	t.logger.Debug("Verifying the cluster capabilities...")
+	caps, err := NewCapabilities(t.kube)
+	if err != nil {
+		return nil, err
+	}
+	if err = caps.Verify(topology, vars); err != nil {
+		return nil, err
+	}
+	return topology, nil
+}
+
+// alternativesHint decorates the missing integrations error with the alternative
+// sets of integrations which satisfy the topology, found by the solver.
+func (t *TopologyBuilder) alternativesHint(
+	err error,
+	cfg *config.Config,
+	vars *Context,
+	i *Integrations,
+	topology *Topology,
+) error {
+	var alternatives strings.Builder
+	i.SetProviderVerifier(t.ProviderVerifier(cfg, vars))
+	solutions, solveErr := i.Solve(topology, t.collection)
+	if solveErr != nil {
+		t.logger.Debug("Unable to solve the integrations requirements",
+			"error", solveErr)
+	} else {
+		solutions.PrintAlternatives(&alternatives, func(name string) string {
+			return fmt.Sprintf("%s integration %s", t.appCtx.Name, name)
+		})
+		alternatives.WriteString("\n")
+	}
+	return fmt.Errorf(`%w
+
+%sTo inspect the alternative sets of integrations satisfying the topology, run:
+
+	%s topology requirements`, err, alternatives.String(), t.appCtx.Name)
+}
+
+// ProviderVerifier returns the verifier of the products proposed by the solver,
+// the cluster capabilities are verified when the cluster can be inspected.
+func (t *TopologyBuilder) ProviderVerifier(
+	cfg *config.Config,
+	vars *Context,
+) ProviderVerifier {
+	caps, err := NewCapabilities(t.kube)
+	if err != nil {
+		t.logger.Debug("Unable to inspect the cluster capabilities",
+			"error", err)
+		caps = nil
+	}
+	return NewProviderVerifier(cfg, t.collection, vars, caps)
+}
+
+// NewTopologyBuilder creates a new TopologyBuilder instance.
+func NewTopologyBuilder(
+	appCtx *api.AppContext,
+	logger *slog.Logger,
+	cfs chartfs.Interface,
+	kube k8s.Interface,
+	integrationNames []string,
+) (*TopologyBuilder, error) {
+	t := &TopologyBuilder{
+		appCtx:           appCtx,
+		logger:           logger,
+		kube:             kube,
+		integrationNames: integrationNames,
+	}
+	// Reading all charts from the informed filesystem.
+	charts, err := cfs.GetAllCharts()
+	if err != nil {
+		return nil, err
+	}
+	// Creating a collection with the charts found.
+	if t.collection, err = NewCollection(appCtx, charts); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
@@ -0,0 +1,209 @@
+package status
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/annotations"
+	"github.com/redhat-appstudio/tssc-cli/pkg/k8s"
+
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/chart"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// CheckpointFilename the ConfigMap key holding the deployment checkpoints.
+	CheckpointFilename = "checkpoints.yaml"
+	// CheckpointLabel identifies the ConfigMap holding the deployment
+	// checkpoints.
+	CheckpointLabel = annotations.RepoURI + "/checkpoints"
+)
+
+// Checkpoint represents a chart successfully deployed by an unfinished
+// deployment, with the digests of its inputs.
+type Checkpoint struct {
+	Namespace    string    `yaml:"namespace"`    // release namespace
+	ChartDigest  string    `yaml:"chartDigest"`  // digest of the chart files
+	ValuesDigest string    `yaml:"valuesDigest"` // digest of rendered values
+	Completed    time.Time `yaml:"completed"`    // chart deployment completed
+}
+
+// Checkpoints represents the progress of the current deployment, the charts
+// deployed so far indexed by name.
+type Checkpoints struct {
+	Started time.Time             `yaml:"started"` // deployment started
+	Charts  map[string]Checkpoint `yaml:"charts"`  // deployed charts
+}
+
+// Done checks whether the chart has been deployed with identical inputs, on the
+// same namespace.
+func (c *Checkpoints) Done(name string, cp Checkpoint) bool {
+	done, exists := c.Charts[name]
+	return exists &&
+		done.Namespace == cp.Namespace &&
+		done.ChartDigest == cp.ChartDigest &&
+		done.ValuesDigest == cp.ValuesDigest
+}
+
+// Record records the chart as deployed, now.
+func (c *Checkpoints) Record(name string, cp Checkpoint) {
+	if c.Charts == nil {
+		c.Charts = map[string]Checkpoint{}
+	}
+	cp.Completed = time.Now().UTC()
+	c.Charts[name] = cp
+}
+
+// ChartDigest calculates the digest of the informed Helm chart, its metadata,
+// default values, templates and files, including the subcharts. The files are
+// sorted by name, therefore the digest is stable.
+func ChartDigest(hc *chart.Chart) (string, error) {
+	h := sha256.New()
+	var digest func(*chart.Chart) error
+	digest = func(c *chart.Chart) error {
+		for _, v := range []any{c.Metadata, c.Values} {
+			payload, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			h.Write(payload)
+		}
+		files := slices.Concat(c.Templates, c.Files)
+		slices.SortStableFunc(files, func(a, b *chart.File) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+		for _, f := range files {
+			fmt.Fprintf(h, "%s\x00%d\x00", f.Name, len(f.Data))
+			h.Write(f.Data)
+		}
+		for _, sub := range c.Dependencies() {
+			if err := digest(sub); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := digest(hc); err != nil {
+		return "", fmt.Errorf("calculating chart digest for %q: %w",
+			hc.Name(), err)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// CheckpointManager the actor responsible for the deployment checkpoints in the
+// cluster, stored in a ConfigMap next to the installer configuration.
+type CheckpointManager struct {
+	kube k8s.Interface // kubernetes client
+	name string        // configmap name
+}
+
+// Name returns the ConfigMap name.
+func (m *CheckpointManager) Name() string {
+	return m.name
+}
+
+// parse parses the deployment checkpoints payload, empty when missing.
+func (m *CheckpointManager) parse(
+	namespace string,
+	payload string,
+) (*Checkpoints, error) {
+	c := &Checkpoints{}
+	if err := yaml.Unmarshal([]byte(payload), c); err != nil {
+		return nil, fmt.Errorf("parsing %s/%s: %w", namespace, m.name, err)
+	}
+	if c.Charts == nil {
+		c.Charts = map[string]Checkpoint{}
+	}
+	return c, nil
+}
+
+// Get retrieves the deployment checkpoints from the informed namespace, empty
+// checkpoints are returned when nothing has been recorded yet.
+func (m *CheckpointManager) Get(
+	ctx context.Context,
+	namespace string,
+) (*Checkpoints, error) {
+	coreClient, err := m.kube.CoreV1ClientSet(namespace)
+	if err != nil {
+		return nil, err
+	}
+	cm, err := coreClient.ConfigMaps(namespace).
+		Get(ctx, m.name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return &Checkpoints{Charts: map[string]Checkpoint{}}, nil
+		}
+		return nil, err
+	}
+	return m.parse(namespace, cm.Data[CheckpointFilename])
+}
+
+// Update applies the mutation on the latest deployment checkpoints in the
+// informed namespace, creating the ConfigMap when it doesn't exist yet.
+// Concurrent changes are not overwritten, on conflict the mutation is applied
+// again on the latest checkpoints. The resulting checkpoints are returned.
+func (m *CheckpointManager) Update(
+	ctx context.Context,
+	namespace string,
+	mutate func(*Checkpoints) error,
+) (*Checkpoints, error) {
+	var c *Checkpoints
+	err := k8s.UpdateConfigMap(
+		ctx,
+		m.kube,
+		namespace,
+		m.name,
+		map[string]string{CheckpointLabel: "true"},
+		func(data map[string]string) (bool, error) {
+			var err error
+			if c, err = m.parse(namespace, data[CheckpointFilename]); err != nil {
+				return false, err
+			}
+			if err = mutate(c); err != nil {
+				return false, err
+			}
+			payload, err := yaml.Marshal(c)
+			if err != nil {
+				return false, err
+			}
+			data[CheckpointFilename] = string(payload)
+			return true, nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Delete removes the deployment checkpoints from the informed namespace, once
+// the deployment is complete.
+func (m *CheckpointManager) Delete(ctx context.Context, namespace string) error {
+	coreClient, err := m.kube.CoreV1ClientSet(namespace)
+	if err != nil {
+		return err
+	}
+	err = coreClient.ConfigMaps(namespace).
+		Delete(ctx, m.name, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// NewCheckpointManager instantiates the CheckpointManager, the ConfigMap is named
+// after the application as "{appName}-checkpoints".
+func NewCheckpointManager(kube k8s.Interface, appName string) *CheckpointManager {
+	return &CheckpointManager{
+		kube: kube,
+		name: fmt.Sprintf("%s-checkpoints", appName),
+	}
+}
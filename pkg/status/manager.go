@@ -0,0 +1,123 @@
+package status
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/annotations"
+	"github.com/redhat-appstudio/tssc-cli/pkg/k8s"
+
+	"gopkg.in/yaml.v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Filename the ConfigMap key holding the deployment state.
+const Filename = "status.yaml"
+
+// Label identifies the ConfigMap holding the deployment state.
+const Label = annotations.RepoURI + "/status"
+
+// Manager the actor responsible for the deployment state in the cluster, stored
+// in a ConfigMap next to the installer configuration.
+type Manager struct {
+	kube k8s.Interface // kubernetes client
+	name string        // configmap name
+}
+
+// Name returns the ConfigMap name.
+func (m *Manager) Name() string {
+	return m.name
+}
+
+// parse parses the deployment state payload, empty when missing.
+func (m *Manager) parse(namespace, payload string) (*Status, error) {
+	s := &Status{}
+	if err := yaml.Unmarshal([]byte(payload), s); err != nil {
+		return nil, fmt.Errorf("parsing %s/%s: %w", namespace, m.name, err)
+	}
+	if s.Charts == nil {
+		s.Charts = map[string]Chart{}
+	}
+	return s, nil
+}
+
+// Get retrieves the deployment state from the informed namespace, an empty
+// status is returned when nothing has been recorded yet.
+func (m *Manager) Get(ctx context.Context, namespace string) (*Status, error) {
+	coreClient, err := m.kube.CoreV1ClientSet(namespace)
+	if err != nil {
+		return nil, err
+	}
+	cm, err := coreClient.ConfigMaps(namespace).
+		Get(ctx, m.name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return &Status{Charts: map[string]Chart{}}, nil
+		}
+		return nil, err
+	}
+	return m.parse(namespace, cm.Data[Filename])
+}
+
+// Update applies the mutation on the latest deployment state in the informed
+// namespace, creating the ConfigMap when it doesn't exist yet. Concurrent
+// changes are not overwritten, on conflict the mutation is applied again on the
+// latest state. The resulting state is returned.
+func (m *Manager) Update(
+	ctx context.Context,
+	namespace string,
+	mutate func(*Status) error,
+) (*Status, error) {
+	var s *Status
+	err := k8s.UpdateConfigMap(
+		ctx,
+		m.kube,
+		namespace,
+		m.name,
+		map[string]string{Label: "true"},
+		func(data map[string]string) (bool, error) {
+			var err error
+			if s, err = m.parse(namespace, data[Filename]); err != nil {
+				return false, err
+			}
+			if err = mutate(s); err != nil {
+				return false, err
+			}
+			payload, err := yaml.Marshal(s)
+			if err != nil {
+				return false, err
+			}
+			data[Filename] = string(payload)
+			return true, nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Delete removes the deployment state from the informed namespace, a missing
+// state is not an error.
+func (m *Manager) Delete(ctx context.Context, namespace string) error {
+	coreClient, err := m.kube.CoreV1ClientSet(namespace)
+	if err != nil {
+		return err
+	}
+	err = coreClient.ConfigMaps(namespace).
+		Delete(ctx, m.name, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// NewManager instantiates the status Manager, the ConfigMap is named after the
+// application as "{appName}-status".
+func NewManager(kube k8s.Interface, appName string) *Manager {
+	return &Manager{
+		kube: kube,
+		name: fmt.Sprintf("%s-status", appName),
+	}
+}
@@ -0,0 +1,170 @@
+package status
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// Installer represents the installer executable which last deployed all charts.
+type Installer struct {
+	Version  string    `yaml:"version"`  // installer version
+	CommitID string    `yaml:"commitID"` // installer commit ID
+	Updated  time.Time `yaml:"updated"`  // last complete deployment
+}
+
+// Chart represents the last deployment of a single Helm chart.
+type Chart struct {
+	Namespace    string    `yaml:"namespace"`       // release namespace
+	ChartVersion string    `yaml:"chartVersion"`    // deployed chart version
+	Revision     int       `yaml:"revision"`        // helm release revision
+	ValuesDigest string    `yaml:"valuesDigest"`    // digest of release values
+	Version      string    `yaml:"version"`         // installer version
+	CommitID     string    `yaml:"commitID"`        // installer commit ID
+	Updated      time.Time `yaml:"updated"`         // release last deployed
+	Outcome      string    `yaml:"outcome"`         // helm release status
+	Error        string    `yaml:"error,omitempty"` // deployment failure
+}
+
+// OutcomeFailed the outcome of a chart which deployment failed, regardless of
+// the release status left by the failure policy, e.g. rolled back.
+const OutcomeFailed = "failed"
+
+// Status represents the installer deployment state recorded in the cluster.
+type Status struct {
+	Installer Installer        `yaml:"installer"` // last complete deployment
+	Charts    map[string]Chart `yaml:"charts"`    // per chart release state
+}
+
+// ValuesDigest calculates the digest of the informed Helm values. The values are
+// serialized as JSON, which sorts the map keys, therefore the digest is stable.
+func ValuesDigest(values map[string]any) (string, error) {
+	payload, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(payload)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// IsEmpty checks whether the status has any deployment recorded.
+func (s *Status) IsEmpty() bool {
+	return s.Installer.Version == "" && len(s.Charts) == 0
+}
+
+// RecordInstaller records the installer version and commit which completed the
+// deployment of all charts.
+func (s *Status) RecordInstaller(version, commitID string) {
+	s.Installer = Installer{
+		Version:  version,
+		CommitID: commitID,
+		Updated:  time.Now().UTC(),
+	}
+}
+
+// RecordRelease records the state of the informed Helm release, deployed by the
+// informed installer version and commit.
+func (s *Status) RecordRelease(
+	rel *release.Release,
+	version string,
+	commitID string,
+) error {
+	digest, err := ValuesDigest(rel.Config)
+	if err != nil {
+		return fmt.Errorf("calculating values digest for %q: %w", rel.Name, err)
+	}
+	c := Chart{
+		Namespace:    rel.Namespace,
+		Revision:     rel.Version,
+		ValuesDigest: digest,
+		Version:      version,
+		CommitID:     commitID,
+	}
+	if rel.Chart != nil && rel.Chart.Metadata != nil {
+		c.ChartVersion = rel.Chart.Metadata.Version
+	}
+	if rel.Info != nil {
+		c.Updated = rel.Info.LastDeployed.Time.UTC()
+		c.Outcome = rel.Info.Status.String()
+	}
+	if s.Charts == nil {
+		s.Charts = map[string]Chart{}
+	}
+	s.Charts[rel.Name] = c
+	return nil
+}
+
+// RecordFailure records the deployment of the informed chart as failed, with
+// the error. The release state previously recorded is kept.
+func (s *Status) RecordFailure(name string, err error) {
+	if s.Charts == nil {
+		s.Charts = map[string]Chart{}
+	}
+	c := s.Charts[name]
+	c.Outcome = OutcomeFailed
+	c.Error = err.Error()
+	c.Updated = time.Now().UTC()
+	s.Charts[name] = c
+}
+
+// Forget removes the informed charts from the recorded state, once their
+// releases are uninstalled.
+func (s *Status) Forget(names ...string) {
+	for _, name := range names {
+		delete(s.Charts, name)
+	}
+}
+
+// shortDigest returns the digest abbreviated for tabular output.
+func shortDigest(digest string) string {
+	const size = len("sha256:") + 12
+	if len(digest) > size {
+		return digest[:size]
+	}
+	return digest
+}
+
+// Print prints the recorded deployment state to the writer as a table.
+func (s *Status) Print(w io.Writer) {
+	if s.Installer.Version != "" {
+		fmt.Fprintf(w, "Installer: %s (commit %q), deployed at %s\n\n",
+			s.Installer.Version,
+			s.Installer.CommitID,
+			s.Installer.Updated.Format(time.RFC3339),
+		)
+	}
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	row := func(a ...any) {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", a...)
+	}
+	row("Chart", "Namespace", "Chart-Version", "Revision", "Outcome",
+		"Updated", "Installer", "Values-Digest")
+
+	names := make([]string, 0, len(s.Charts))
+	for name := range s.Charts {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		c := s.Charts[name]
+		row(
+			name,
+			c.Namespace,
+			c.ChartVersion,
+			fmt.Sprintf("%d", c.Revision),
+			c.Outcome,
+			c.Updated.Format(time.RFC3339),
+			c.Version,
+			shortDigest(c.ValuesDigest),
+		)
+	}
+	table.Flush()
+}
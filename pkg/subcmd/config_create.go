@@ -0,0 +1,163 @@
+package subcmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/chartfs"
+	"github.com/redhat-appstudio/tssc-cli/pkg/config"
+	"github.com/redhat-appstudio/tssc-cli/pkg/flags"
+
+	"github.com/redhat-appstudio/helmet/api"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// overlayFlags injects the configuration overlays and profiles flags.
+func overlayFlags(p *pflag.FlagSet, overlays, profiles *[]string) {
+	p.StringArrayVar(
+		overlays,
+		"overlay",
+		[]string{},
+		"Configuration overlay file merged on top of the configuration, "+
+			"can be repeated",
+	)
+	p.StringArrayVar(
+		profiles,
+		"profile",
+		[]string{},
+		fmt.Sprintf("Named configuration profile, stored as %q, merged on "+
+			"top of the configuration before the overlays, can be repeated",
+			config.ProfilePath("{name}")),
+	)
+}
+
+// overlayPaths returns the overlay paths to merge in order, the named profiles
+// first and then the overlay files.
+func overlayPaths(overlays, profiles []string) []string {
+	paths := make([]string, 0, len(profiles)+len(overlays))
+	for _, name := range profiles {
+		paths = append(paths, config.ProfilePath(name))
+	}
+	return append(paths, overlays...)
+}
+
+// ConfigCreate decorates the framework config subcommand, merging the overlays
+// and profiles on top of the informed configuration file, and validating the
+// result against the schemas before it's created in the cluster.
+type ConfigCreate struct {
+	appCtx *api.AppContext   // application context
+	cfs    chartfs.Interface // installer filesystem
+
+	overlays []string // configuration overlay files
+	profiles []string // configuration profile names
+	merged   string   // temporary file with the merged configuration
+}
+
+// namespace returns the configuration namespace, the "--instance" flag selects
+// the namespace when "--namespace" is not informed, both must agree otherwise.
+func (c *ConfigCreate) namespace(cmd *cobra.Command) (string, error) {
+	namespace, err := cmd.Flags().GetString("namespace")
+	if err != nil {
+		return "", err
+	}
+	f, err := flags.NewFlagsFromCommand(cmd)
+	if err != nil || f.Instance == "" {
+		return namespace, err
+	}
+	if !cmd.Flags().Changed("namespace") {
+		return f.Instance, cmd.Flags().Set("namespace", f.Instance)
+	}
+	if namespace != f.Instance {
+		return "", fmt.Errorf("--namespace %q and --%s %q must match",
+			namespace, flags.InstanceFlag, f.Instance)
+	}
+	return namespace, nil
+}
+
+// preRun loads the configuration with the overlays, and validates it. When there
+// are overlays, the merged configuration is stored on a temporary file, which
+// replaces the informed arguments.
+func (c *ConfigCreate) preRun(cmd *cobra.Command, args []string) ([]string, error) {
+	create, err := cmd.Flags().GetBool("create")
+	if err != nil {
+		return nil, err
+	}
+	paths := overlayPaths(c.overlays, c.profiles)
+	if !create {
+		if len(paths) > 0 {
+			return nil, fmt.Errorf(
+				"--overlay and --profile flags can only be used with --create")
+		}
+		return args, nil
+	}
+	namespace, err := c.namespace(cmd)
+	if err != nil {
+		return nil, err
+	}
+	configPath := config.DefaultRelativeConfigPath
+	if len(args) > 0 {
+		configPath = args[0]
+	}
+	cfg, err := config.NewConfigFromFiles(
+		c.cfs, configPath, paths, namespace, c.appCtx.Name)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		if err = validateSchema(c.cfs, cfg); err != nil {
+			return nil, fmt.Errorf("%s: %w", configPath, err)
+		}
+		return args, nil
+	}
+
+	// The violations are located on the merged configuration, as shown by the
+	// global "--dry-run" flag.
+	schema, err := config.NewSchema(c.cfs)
+	if err != nil {
+		return nil, err
+	}
+	if err = schema.ValidatePayload(cfg); err != nil {
+		return nil, fmt.Errorf("merged configuration: %w", err)
+	}
+	f, err := os.CreateTemp("", fmt.Sprintf("%s-config-*.yaml", c.appCtx.Name))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	c.merged = f.Name()
+	if _, err = f.WriteString(cfg.String()); err != nil {
+		return nil, err
+	}
+	return []string{c.merged}, nil
+}
+
+// Decorate injects the overlay flags, and wraps the informed config subcommand
+// pre-run and run.
+func (c *ConfigCreate) Decorate(cmd *cobra.Command) {
+	overlayFlags(cmd.Flags(), &c.overlays, &c.profiles)
+
+	preRunE := cmd.PreRunE
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		args, err := c.preRun(cmd, args)
+		if err == nil && preRunE != nil {
+			err = preRunE(cmd, args)
+		}
+		if err != nil && c.merged != "" {
+			_ = os.Remove(c.merged)
+		}
+		return err
+	}
+	runE := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if c.merged != "" {
+			defer os.Remove(c.merged)
+		}
+		return runE(cmd, args)
+	}
+}
+
+// NewConfigCreate instantiates the ConfigCreate.
+func NewConfigCreate(appCtx *api.AppContext, cfs chartfs.Interface) *ConfigCreate {
+	return &ConfigCreate{appCtx: appCtx, cfs: cfs}
+}
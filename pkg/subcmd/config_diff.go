@@ -0,0 +1,181 @@
+package subcmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/chartfs"
+	"github.com/redhat-appstudio/tssc-cli/pkg/config"
+	"github.com/redhat-appstudio/tssc-cli/pkg/flags"
+	"github.com/redhat-appstudio/tssc-cli/pkg/k8s"
+
+	"github.com/redhat-appstudio/helmet/api"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// ConfigDiff represents the "config diff" subcommand, it compares a local or the
+// embedded configuration with the cluster configuration.
+type ConfigDiff struct {
+	cmd    *cobra.Command    // cobra command
+	appCtx *api.AppContext   // application context
+	cfs    chartfs.Interface // installer filesystem
+	flags  *flags.Flags      // global flags
+	logger *slog.Logger      // application logger
+
+	manager *config.ConfigMapManager // cluster configuration manager
+	cluster *config.Config           // cluster configuration
+	local   *config.Config           // local configuration
+
+	configPath string   // local configuration file path
+	overlays   []string // local configuration overlay files
+	profiles   []string // local configuration profile names
+	output     string   // output format
+}
+
+var _ api.SubCommand = (*ConfigDiff)(nil)
+
+// ErrConfigDrift the local configuration differs from the cluster, the
+// application exits with ExitCodeDrift.
+var ErrConfigDrift = errors.New("configuration drift detected")
+
+// ExitCodeDrift exit status for configuration drift, distinct from errors.
+const ExitCodeDrift = 2
+
+const (
+	// diffOutputText human readable diff output.
+	diffOutputText = "text"
+	// diffOutputJSON machine readable diff output.
+	diffOutputJSON = "json"
+)
+
+const configDiffDesc = `
+Compares the local configuration file, or the embedded default configuration,
+with the cluster configuration. The overlays and profiles are merged on top of
+the local configuration, as "%[1]s config --create" does.
+
+The comparison is semantic, it shows the changes the local configuration
+would make on the cluster: settings changes, products added, removed, enabled or
+disabled, namespace changes and product properties changes. Formatting and
+comments are not taken into account.
+
+The subcommand exits with status 2 when the configurations differ, and with
+status 1 on errors, so it can be used to detect drift on CI pipelines.
+
+Examples:
+
+	$ %[1]s config diff
+	$ %[1]s config diff --config config.yaml
+	$ %[1]s config diff --config config.yaml --output json
+	$ %[1]s config diff --profile crc --overlay cluster.yaml
+`
+
+// Cmd exposes the cobra instance.
+func (c *ConfigDiff) Cmd() *cobra.Command {
+	return c.cmd
+}
+
+// log returns a decorated logger.
+func (c *ConfigDiff) log() *slog.Logger {
+	return c.flags.LoggerWith(c.logger.With("config-path", c.configPath))
+}
+
+// PersistentFlags injects the sub-command flags.
+func (c *ConfigDiff) PersistentFlags(p *pflag.FlagSet) {
+	p.StringVar(
+		&c.configPath,
+		"config",
+		config.DefaultRelativeConfigPath,
+		"Local configuration file, uses the embedded configuration by default",
+	)
+	overlayFlags(p, &c.overlays, &c.profiles)
+	p.StringVarP(
+		&c.output,
+		"output",
+		"o",
+		diffOutputText,
+		fmt.Sprintf("Output format (%s, %s)", diffOutputText, diffOutputJSON),
+	)
+}
+
+// Complete loads the cluster and the local configurations.
+func (c *ConfigDiff) Complete(_ []string) error {
+	var err error
+	if c.flags, err = flags.NewFlagsFromCommand(c.cmd); err != nil {
+		return err
+	}
+	c.logger = c.flags.GetLogger(os.Stdout)
+	c.manager = config.NewConfigMapManager(
+		k8s.NewKube(c.flags), c.appCtx.Name, c.flags.Instance)
+	if c.cluster, err = bootstrapConfig(
+		c.cmd.Context(), c.appCtx, c.manager,
+	); err != nil {
+		return err
+	}
+
+	// The local configuration shares the cluster namespace, so products without
+	// namespace default to the same value on both sides.
+	c.log().Debug("Loading the local configuration")
+	c.local, err = config.NewConfigFromFiles(
+		c.cfs,
+		c.configPath,
+		overlayPaths(c.overlays, c.profiles),
+		c.cluster.Namespace(),
+		c.appCtx.Name,
+	)
+	return err
+}
+
+// Validate asserts the output format is supported.
+func (c *ConfigDiff) Validate() error {
+	switch c.output {
+	case diffOutputText, diffOutputJSON:
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format %q", c.output)
+	}
+}
+
+// Run compares the configurations, printing the differences.
+func (c *ConfigDiff) Run() error {
+	c.log().Debug("Comparing the cluster and local configurations")
+	diff := config.NewDiff(c.cluster, c.local)
+
+	if c.output == diffOutputJSON {
+		if err := diff.PrintJSON(os.Stdout); err != nil {
+			return err
+		}
+	} else if diff.HasDrift() {
+		fmt.Printf("Changes from ConfigMap %s/%s to %q:\n\n",
+			c.cluster.Namespace(), c.manager.Name(), c.configPath)
+		diff.PrintText(os.Stdout)
+	} else {
+		fmt.Printf("No changes between ConfigMap %s/%s and %q.\n",
+			c.cluster.Namespace(), c.manager.Name(), c.configPath)
+	}
+
+	if diff.HasDrift() {
+		return fmt.Errorf("%w: %d change(s)", ErrConfigDrift, len(diff.Changes))
+	}
+	return nil
+}
+
+// NewConfigDiff instantiates the "config diff" subcommand.
+func NewConfigDiff(appCtx *api.AppContext, cfs chartfs.Interface) *ConfigDiff {
+	c := &ConfigDiff{
+		cmd: &cobra.Command{
+			Use:          "diff",
+			Short:        "Compares a local configuration with the cluster",
+			Long:         fmt.Sprintf(configDiffDesc, appCtx.Name),
+			Args:         cobra.NoArgs,
+			SilenceUsage: true,
+		},
+		appCtx: appCtx,
+		cfs:    cfs,
+		logger: slog.Default(),
+	}
+	c.PersistentFlags(c.cmd.PersistentFlags())
+	return c
+}
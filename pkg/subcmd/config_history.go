@@ -0,0 +1,98 @@
+package subcmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/config"
+	"github.com/redhat-appstudio/tssc-cli/pkg/flags"
+	"github.com/redhat-appstudio/tssc-cli/pkg/k8s"
+
+	"github.com/redhat-appstudio/helmet/api"
+	"github.com/spf13/cobra"
+)
+
+// ConfigHistory represents the "config history" subcommand, it lists the
+// revisions of the cluster configuration.
+type ConfigHistory struct {
+	cmd    *cobra.Command  // cobra command
+	appCtx *api.AppContext // application context
+	flags  *flags.Flags    // global flags
+	logger *slog.Logger    // application logger
+
+	cfg     *config.Config         // cluster configuration
+	history *config.HistoryManager // configuration history manager
+}
+
+var _ api.SubCommand = (*ConfigHistory)(nil)
+
+const configHistoryDesc = `
+Lists the last revisions of the cluster configuration, newest first.
+
+Each change made by the "%[1]s config" subcommands, the integrations, upgrades
+and the MCP server tools is recorded with its author, timestamp and a summary of
+the changes. The last %[2]d revisions are kept, the current revision is marked
+with an asterisk. Use "%[1]s config rollback" to restore a previous revision.
+`
+
+// Cmd exposes the cobra instance.
+func (c *ConfigHistory) Cmd() *cobra.Command {
+	return c.cmd
+}
+
+// Complete loads the cluster configuration, to locate the history.
+func (c *ConfigHistory) Complete(_ []string) error {
+	var err error
+	if c.flags, err = flags.NewFlagsFromCommand(c.cmd); err != nil {
+		return err
+	}
+	c.logger = c.flags.GetLogger(os.Stdout)
+	kube := k8s.NewKube(c.flags)
+	if c.cfg, err = bootstrapConfig(
+		c.cmd.Context(),
+		c.appCtx,
+		config.NewConfigMapManager(kube, c.appCtx.Name, c.flags.Instance),
+	); err != nil {
+		return err
+	}
+	c.history = config.NewHistoryManager(kube, c.appCtx.Name)
+	return nil
+}
+
+// Validate is a no-op.
+func (c *ConfigHistory) Validate() error {
+	return nil
+}
+
+// Run prints the configuration revisions.
+func (c *ConfigHistory) Run() error {
+	c.flags.LoggerWith(c.logger).Debug("Loading the configuration history")
+	h, err := c.history.Get(c.cmd.Context(), c.cfg.Namespace())
+	if err != nil {
+		return err
+	}
+	if len(h.Revisions) == 0 {
+		fmt.Printf("No configuration revision recorded in ConfigMap %s/%s.\n",
+			c.cfg.Namespace(), c.history.Name())
+		return nil
+	}
+	h.Print(os.Stdout)
+	return nil
+}
+
+// NewConfigHistory instantiates the "config history" subcommand.
+func NewConfigHistory(appCtx *api.AppContext) *ConfigHistory {
+	return &ConfigHistory{
+		cmd: &cobra.Command{
+			Use:   "history",
+			Short: "Lists the cluster configuration revisions",
+			Long: fmt.Sprintf(
+				configHistoryDesc, appCtx.Name, config.DefaultHistoryLimit),
+			Args:         cobra.NoArgs,
+			SilenceUsage: true,
+		},
+		appCtx: appCtx,
+		logger: slog.Default(),
+	}
+}
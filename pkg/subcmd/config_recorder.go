@@ -0,0 +1,227 @@
+package subcmd
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/chartfs"
+	"github.com/redhat-appstudio/tssc-cli/pkg/config"
+	"github.com/redhat-appstudio/tssc-cli/pkg/constants"
+	"github.com/redhat-appstudio/tssc-cli/pkg/flags"
+	"github.com/redhat-appstudio/tssc-cli/pkg/k8s"
+
+	"github.com/redhat-appstudio/helmet/api"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// ConfigRecorder decorates framework subcommands which change the cluster
+// configuration, recording the changes on the configuration history.
+type ConfigRecorder struct {
+	appCtx *api.AppContext // application context
+}
+
+// wrap snapshots the cluster configuration before and after the informed
+// function, and records the change when any.
+func (r *ConfigRecorder) wrap(
+	fn func(*cobra.Command, []string) error,
+) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		f, err := flags.NewFlagsFromCommand(cmd)
+		if err != nil {
+			return err
+		}
+		if f.DryRun {
+			return fn(cmd, args)
+		}
+		logger := f.LoggerWith(f.GetLogger(os.Stdout).With("type", "history"))
+		manager := config.NewConfigMapManager(
+			k8s.NewKube(f), r.appCtx.Name, f.Instance)
+
+		// The configuration may not exist before, i.e. when it's being created.
+		previous, err := manager.GetConfig(cmd.Context())
+		if err != nil {
+			previous = nil
+		}
+		if err = fn(cmd, args); err != nil {
+			return err
+		}
+		current, err := manager.GetConfig(cmd.Context())
+		if err != nil {
+			logger.Debug("Unable to load the current configuration", "error", err)
+			return nil
+		}
+		recordConfig(cmd.Context(), r.appCtx, logger, f, author(cmd), "",
+			previous, current)
+		return nil
+	}
+}
+
+// Decorate wraps the informed subcommand execution, and its persistent post-run
+// which is inherited by nested subcommands.
+func (r *ConfigRecorder) Decorate(c *cobra.Command) {
+	if c.RunE != nil {
+		c.RunE = r.wrap(c.RunE)
+	}
+	if c.PersistentPostRunE != nil {
+		c.PersistentPostRunE = r.wrap(c.PersistentPostRunE)
+	}
+}
+
+// NewConfigRecorder instantiates the ConfigRecorder.
+func NewConfigRecorder(appCtx *api.AppContext) *ConfigRecorder {
+	return &ConfigRecorder{appCtx: appCtx}
+}
+
+// ConfigWatcher decorates the framework MCP server subcommand, recording the
+// configuration changes made by the MCP tools on the configuration history. The
+// cluster configuration is watched while the server is running, invalid changes
+// or changes which violate the schemas are reverted to the previous configuration.
+type ConfigWatcher struct {
+	appCtx *api.AppContext   // application context
+	cfs    chartfs.Interface // installer filesystem
+}
+
+// watchRetryInterval the interval before watching the configuration again.
+const watchRetryInterval = 5 * time.Second
+
+// watch records every configuration change until the context is done. Only the
+// installation namespace is watched, informed by the instance flag or where the
+// configuration is found; when neither is known, all namespaces are watched and
+// each change compared with the previous configuration of its namespace. The
+// MCP server communicates over STDIO, so the logger must not use the standard
+// output.
+func (w *ConfigWatcher) watch(
+	ctx context.Context,
+	logger *slog.Logger,
+	f *flags.Flags,
+) {
+	kube := k8s.NewKube(f)
+	manager := config.NewConfigMapManager(kube, w.appCtx.Name, f.Instance)
+	schema, err := config.NewSchema(w.cfs)
+	if err != nil {
+		logger.Warn("Unable to load the configuration schemas", "error", err)
+		return
+	}
+	manager.SetSchema(schema)
+
+	// Previous configuration by namespace, the baseline to record changes.
+	previous := map[string]*config.Config{}
+	namespace := f.Instance
+	if cfg, err := manager.GetConfig(ctx); err == nil {
+		namespace = cfg.Namespace()
+		previous[namespace] = cfg
+	}
+	logger = logger.With("namespace", namespace)
+
+	resourceVersion := ""
+	for ctx.Err() == nil {
+		coreClient, err := kube.CoreV1ClientSet(namespace)
+		if err != nil {
+			logger.Warn("Unable to watch the configuration", "error", err)
+			return
+		}
+		watcher, err := coreClient.ConfigMaps(namespace).Watch(ctx, metav1.ListOptions{
+			LabelSelector:   config.Selector,
+			FieldSelector:   "metadata.name=" + manager.Name(),
+			ResourceVersion: resourceVersion,
+		})
+		if err != nil {
+			logger.Debug("Unable to watch the configuration", "error", err)
+			resourceVersion = ""
+			select {
+			case <-ctx.Done():
+			case <-time.After(watchRetryInterval):
+			}
+			continue
+		}
+		for event := range watcher.ResultChan() {
+			if event.Type == watch.Error {
+				resourceVersion = ""
+				break
+			}
+			cm, ok := event.Object.(*corev1.ConfigMap)
+			if !ok {
+				continue
+			}
+			resourceVersion = cm.GetResourceVersion()
+			if event.Type == watch.Deleted {
+				delete(previous, cm.GetNamespace())
+				continue
+			}
+			if event.Type != watch.Added && event.Type != watch.Modified {
+				continue
+			}
+			current, err := config.NewConfigFromBytes(
+				[]byte(cm.Data[constants.ConfigFilename]),
+				cm.GetNamespace(),
+				w.appCtx.Name,
+			)
+			if err == nil {
+				err = schema.Validate(current)
+			}
+			if err != nil {
+				w.restore(ctx, logger, manager, cm,
+					previous[cm.GetNamespace()], err)
+				continue
+			}
+			recordConfig(ctx, w.appCtx, logger, f, config.AuthorMCP, "",
+				previous[cm.GetNamespace()], current)
+			previous[cm.GetNamespace()] = current
+		}
+		watcher.Stop()
+	}
+}
+
+// restore reverts an invalid configuration change to the previous configuration
+// observed, the change is never recorded. Without a previous configuration the
+// change is only reported.
+func (w *ConfigWatcher) restore(
+	ctx context.Context,
+	logger *slog.Logger,
+	manager *config.ConfigMapManager,
+	cm *corev1.ConfigMap,
+	previous *config.Config,
+	invalid error,
+) {
+	if previous == nil {
+		logger.Warn("The configuration changed is invalid", "error", invalid)
+		return
+	}
+	logger.Warn("The configuration changed is invalid, restoring the previous "+
+		"configuration", "error", invalid)
+	if err := manager.Restore(ctx, cm, previous); err != nil {
+		logger.Warn("Unable to restore the previous configuration",
+			"error", err)
+	}
+}
+
+// Decorate wraps the informed MCP server subcommand execution.
+func (w *ConfigWatcher) Decorate(c *cobra.Command) {
+	runE := c.RunE
+	c.RunE = func(cmd *cobra.Command, args []string) error {
+		f, err := flags.NewFlagsFromCommand(cmd)
+		if err != nil {
+			return err
+		}
+		if !f.DryRun {
+			ctx, cancel := context.WithCancel(cmd.Context())
+			defer cancel()
+			logger := f.LoggerWith(f.GetLogger(os.Stderr).With("type", "history"))
+			go w.watch(ctx, logger, f)
+		}
+		return runE(cmd, args)
+	}
+}
+
+// NewConfigWatcher instantiates the ConfigWatcher.
+func NewConfigWatcher(
+	appCtx *api.AppContext,
+	cfs chartfs.Interface,
+) *ConfigWatcher {
+	return &ConfigWatcher{appCtx: appCtx, cfs: cfs}
+}
@@ -0,0 +1,139 @@
+package subcmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/chartfs"
+	"github.com/redhat-appstudio/tssc-cli/pkg/config"
+	"github.com/redhat-appstudio/tssc-cli/pkg/flags"
+	"github.com/redhat-appstudio/tssc-cli/pkg/k8s"
+
+	"github.com/redhat-appstudio/helmet/api"
+	"github.com/spf13/cobra"
+)
+
+// ConfigRollback represents the "config rollback" subcommand, it restores a
+// previous revision of the cluster configuration.
+type ConfigRollback struct {
+	cmd    *cobra.Command    // cobra command
+	appCtx *api.AppContext   // application context
+	cfs    chartfs.Interface // installer filesystem
+	flags  *flags.Flags      // global flags
+	logger *slog.Logger      // application logger
+
+	manager *config.ConfigMapManager // cluster configuration manager
+	history *config.HistoryManager   // configuration history manager
+	cfg     *config.Config           // cluster configuration
+
+	revision int            // informed revision number
+	restored *config.Config // configuration restored from the revision
+}
+
+var _ api.SubCommand = (*ConfigRollback)(nil)
+
+const configRollbackDesc = `
+Restores the cluster configuration to a previous revision, listed by
+"%[1]s config history".
+
+The restored configuration is validated before the cluster is updated, and the
+rollback is recorded as a new revision. Use the global "--dry-run" flag to
+inspect the configuration without changing the cluster.
+
+Examples:
+
+	$ %[1]s config history
+	$ %[1]s config rollback 3
+`
+
+// Cmd exposes the cobra instance.
+func (c *ConfigRollback) Cmd() *cobra.Command {
+	return c.cmd
+}
+
+// log returns a decorated logger.
+func (c *ConfigRollback) log() *slog.Logger {
+	return c.flags.LoggerWith(c.logger.With("revision", c.revision))
+}
+
+// Complete loads the cluster configuration and parses the informed revision.
+func (c *ConfigRollback) Complete(args []string) error {
+	var err error
+	if c.flags, err = flags.NewFlagsFromCommand(c.cmd); err != nil {
+		return err
+	}
+	c.logger = c.flags.GetLogger(os.Stdout)
+	kube := k8s.NewKube(c.flags)
+	if c.manager, err = newConfigMapManager(
+		c.appCtx, c.cfs, kube, c.flags.Instance,
+	); err != nil {
+		return err
+	}
+	if c.cfg, err = bootstrapConfig(
+		c.cmd.Context(), c.appCtx, c.manager,
+	); err != nil {
+		return err
+	}
+	c.history = config.NewHistoryManager(kube, c.appCtx.Name)
+
+	if c.revision, err = strconv.Atoi(args[0]); err != nil {
+		return fmt.Errorf("invalid revision %q: %w", args[0], err)
+	}
+	return nil
+}
+
+// Validate loads the informed revision, making sure the configuration is valid.
+func (c *ConfigRollback) Validate() error {
+	h, err := c.history.Get(c.cmd.Context(), c.cfg.Namespace())
+	if err != nil {
+		return err
+	}
+	revision, err := h.Get(c.revision)
+	if err != nil {
+		return err
+	}
+	if c.restored, err = config.NewConfigFromBytes(
+		[]byte(revision.Config), c.cfg.Namespace(), c.appCtx.Name,
+	); err != nil {
+		return fmt.Errorf("revision %d: %w", c.revision, err)
+	}
+	return c.manager.Validate(c.restored)
+}
+
+// Run restores the configuration revision in the cluster.
+func (c *ConfigRollback) Run() error {
+	diff := config.NewDiff(c.cfg, c.restored)
+	if !diff.HasDrift() {
+		fmt.Printf("The configuration already matches revision %d.\n",
+			c.revision)
+		return nil
+	}
+	fmt.Printf("Rolling back the configuration to revision %d:\n\n", c.revision)
+	diff.PrintText(os.Stdout)
+	fmt.Println()
+
+	c.log().Debug("Restoring the configuration revision")
+	return updateConfig(c.cmd, c.appCtx, c.log(), c.flags, c.manager,
+		c.restored, fmt.Sprintf("rollback to revision %d", c.revision))
+}
+
+// NewConfigRollback instantiates the "config rollback" subcommand.
+func NewConfigRollback(
+	appCtx *api.AppContext,
+	cfs chartfs.Interface,
+) *ConfigRollback {
+	return &ConfigRollback{
+		cmd: &cobra.Command{
+			Use:          "rollback <revision>",
+			Short:        "Restores a previous cluster configuration revision",
+			Long:         fmt.Sprintf(configRollbackDesc, appCtx.Name),
+			Args:         cobra.ExactArgs(1),
+			SilenceUsage: true,
+		},
+		appCtx: appCtx,
+		cfs:    cfs,
+		logger: slog.Default(),
+	}
+}
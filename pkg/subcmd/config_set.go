@@ -0,0 +1,177 @@
+package subcmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/chartfs"
+	"github.com/redhat-appstudio/tssc-cli/pkg/config"
+	"github.com/redhat-appstudio/tssc-cli/pkg/flags"
+	"github.com/redhat-appstudio/tssc-cli/pkg/k8s"
+
+	"github.com/redhat-appstudio/helmet/api"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigSet represents the "config set" subcommand, it changes a single property
+// of the cluster configuration addressed by a dotted path.
+type ConfigSet struct {
+	cmd    *cobra.Command    // cobra command
+	appCtx *api.AppContext   // application context
+	cfs    chartfs.Interface // installer filesystem
+	flags  *flags.Flags      // global flags
+	logger *slog.Logger      // application logger
+
+	manager *config.ConfigMapManager // cluster configuration manager
+	cfg     *config.Config           // installer configuration
+
+	path   string // informed dotted path
+	value  any    // typed value, parsed as YAML
+	create bool   // adds the addressed product when missing
+}
+
+var _ api.SubCommand = (*ConfigSet)(nil)
+
+const configSetDesc = `
+Sets a property of the cluster configuration, addressed by a dotted path.
+
+The path is relative to the configuration root key, products are addressed by
+index, or by their exact name or key name. Every key on the path is matched
+exactly, case included. The value is parsed as YAML, so
+booleans, numbers, lists and maps are stored with their types; map values are
+applied key by key. Comments and anchors in the configuration are preserved.
+
+Missing keys are created, while products addressed by a name not present in the
+configuration are refused. With "--create" the product is appended to the
+products list, its name must be provided by the installer charts.
+
+The resulting configuration is validated before the cluster is updated, as well
+as checked against the settings schema and the product properties schemas, use
+the global "--dry-run" flag to inspect the result without changing the cluster.
+
+Examples:
+
+	$ %[1]s config set settings.crc true
+	$ %[1]s config set "products.Developer Hub.properties.authProvider" github
+	$ %[1]s config set products.3.enabled false
+	$ %[1]s config set "products.Developer Hub.properties.RBAC.adminUsers" "[admin]"
+	$ %[1]s config set --create "products.Developer Hub" "{enabled: true}"
+`
+
+// Cmd exposes the cobra instance.
+func (c *ConfigSet) Cmd() *cobra.Command {
+	return c.cmd
+}
+
+// PersistentFlags injects the sub-command flags.
+func (c *ConfigSet) PersistentFlags(p *pflag.FlagSet) {
+	p.BoolVar(
+		&c.create,
+		"create",
+		false,
+		"Adds the product addressed by name when missing from the configuration",
+	)
+}
+
+// log returns a decorated logger.
+func (c *ConfigSet) log() *slog.Logger {
+	return c.flags.LoggerWith(c.logger.With("path", c.path))
+}
+
+// Complete loads the cluster configuration and parses the informed value.
+func (c *ConfigSet) Complete(args []string) error {
+	var err error
+	if c.flags, err = flags.NewFlagsFromCommand(c.cmd); err != nil {
+		return err
+	}
+	c.logger = c.flags.GetLogger(os.Stdout)
+	if c.manager, err = newConfigMapManager(
+		c.appCtx, c.cfs, k8s.NewKube(c.flags), c.flags.Instance,
+	); err != nil {
+		return err
+	}
+	if c.cfg, err = bootstrapConfig(
+		c.cmd.Context(), c.appCtx, c.manager,
+	); err != nil {
+		return err
+	}
+
+	c.path = args[0]
+	if err = yaml.Unmarshal([]byte(args[1]), &c.value); err != nil {
+		return fmt.Errorf("parsing value %q: %w", args[1], err)
+	}
+	return nil
+}
+
+// Validate asserts the informed path is not empty.
+func (c *ConfigSet) Validate() error {
+	if c.path == "" {
+		return fmt.Errorf("%w: empty path", config.ErrInvalidConfig)
+	}
+	return nil
+}
+
+// createProduct appends the product addressed by name on the path, when missing
+// from the configuration. The name must be provided by the installer charts.
+func (c *ConfigSet) createProduct() error {
+	name := c.cfg.PathProduct(c.path)
+	if name == "" {
+		return nil
+	}
+	if _, err := c.cfg.KeyPath(c.path); !errors.Is(err, config.ErrProductNotFound) {
+		return err
+	}
+	schema, err := config.NewSchema(c.cfs)
+	if err != nil {
+		return err
+	}
+	if err = schema.ValidateProductName(name); err != nil {
+		return err
+	}
+	c.log().Debug("Adding product to the configuration", "product", name)
+	return c.cfg.SetProduct(name, config.Product{})
+}
+
+// Run updates the configuration property, and the cluster configuration.
+func (c *ConfigSet) Run() error {
+	if c.create {
+		if err := c.createProduct(); err != nil {
+			return err
+		}
+	}
+	c.log().Debug("Setting configuration property")
+	if err := c.cfg.SetPath(c.path, c.value); err != nil {
+		if errors.Is(err, config.ErrProductNotFound) {
+			return fmt.Errorf("%w, use --create to add it", err)
+		}
+		return err
+	}
+	c.cfg.ApplyDefaults()
+	if err := c.cfg.Validate(); err != nil {
+		return err
+	}
+	return updateConfig(
+		c.cmd, c.appCtx, c.log(), c.flags, c.manager, c.cfg, "")
+}
+
+// NewConfigSet instantiates the "config set" subcommand.
+func NewConfigSet(appCtx *api.AppContext, cfs chartfs.Interface) *ConfigSet {
+	c := &ConfigSet{
+		cmd: &cobra.Command{
+			Use:          "set <path> <value>",
+			Short:        "Sets a cluster configuration property",
+			Long:         fmt.Sprintf(configSetDesc, appCtx.Name),
+			Args:         cobra.ExactArgs(2),
+			SilenceUsage: true,
+		},
+		appCtx: appCtx,
+		cfs:    cfs,
+		logger: slog.Default(),
+	}
+	c.PersistentFlags(c.cmd.PersistentFlags())
+	return c
+}
@@ -0,0 +1,111 @@
+package subcmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/chartfs"
+	"github.com/redhat-appstudio/tssc-cli/pkg/config"
+	"github.com/redhat-appstudio/tssc-cli/pkg/flags"
+	"github.com/redhat-appstudio/tssc-cli/pkg/k8s"
+
+	"github.com/redhat-appstudio/helmet/api"
+	"github.com/spf13/cobra"
+)
+
+// ConfigUnset represents the "config unset" subcommand, it removes a single
+// property of the cluster configuration addressed by a dotted path.
+type ConfigUnset struct {
+	cmd    *cobra.Command    // cobra command
+	appCtx *api.AppContext   // application context
+	cfs    chartfs.Interface // installer filesystem
+	flags  *flags.Flags      // global flags
+	logger *slog.Logger      // application logger
+
+	manager *config.ConfigMapManager // cluster configuration manager
+	cfg     *config.Config           // installer configuration
+
+	path    string // informed dotted path
+	keyPath string // configuration key path
+}
+
+var _ api.SubCommand = (*ConfigUnset)(nil)
+
+const configUnsetDesc = `
+Removes a property from the cluster configuration, addressed by a dotted path.
+
+The path follows the same rules as "%[1]s config set". The resulting
+configuration is validated before the cluster is updated, use the global
+"--dry-run" flag to inspect the result without changing the cluster.
+
+Examples:
+
+	$ %[1]s config unset settings.ci.debug
+	$ %[1]s config unset "products.Developer Hub.properties.catalogURL"
+`
+
+// Cmd exposes the cobra instance.
+func (c *ConfigUnset) Cmd() *cobra.Command {
+	return c.cmd
+}
+
+// log returns a decorated logger.
+func (c *ConfigUnset) log() *slog.Logger {
+	return c.flags.LoggerWith(c.logger.With("path", c.path))
+}
+
+// Complete loads the cluster configuration.
+func (c *ConfigUnset) Complete(args []string) error {
+	var err error
+	if c.flags, err = flags.NewFlagsFromCommand(c.cmd); err != nil {
+		return err
+	}
+	c.logger = c.flags.GetLogger(os.Stdout)
+	if c.manager, err = newConfigMapManager(
+		c.appCtx, c.cfs, k8s.NewKube(c.flags), c.flags.Instance,
+	); err != nil {
+		return err
+	}
+	if c.cfg, err = bootstrapConfig(
+		c.cmd.Context(), c.appCtx, c.manager,
+	); err != nil {
+		return err
+	}
+	c.path = args[0]
+	return nil
+}
+
+// Validate resolves the informed path against the configuration.
+func (c *ConfigUnset) Validate() error {
+	var err error
+	c.keyPath, err = c.cfg.KeyPath(c.path)
+	return err
+}
+
+// Run removes the configuration property, and updates the cluster
+// configuration.
+func (c *ConfigUnset) Run() error {
+	c.log().Debug("Removing configuration property", "key-path", c.keyPath)
+	if err := c.cfg.Unset(c.keyPath); err != nil {
+		return err
+	}
+	return updateConfig(
+		c.cmd, c.appCtx, c.log(), c.flags, c.manager, c.cfg, "")
+}
+
+// NewConfigUnset instantiates the "config unset" subcommand.
+func NewConfigUnset(appCtx *api.AppContext, cfs chartfs.Interface) *ConfigUnset {
+	return &ConfigUnset{
+		cmd: &cobra.Command{
+			Use:          "unset <path>",
+			Short:        "Removes a cluster configuration property",
+			Long:         fmt.Sprintf(configUnsetDesc, appCtx.Name),
+			Args:         cobra.ExactArgs(1),
+			SilenceUsage: true,
+		},
+		appCtx: appCtx,
+		cfs:    cfs,
+		logger: slog.Default(),
+	}
+}
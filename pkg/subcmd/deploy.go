@@ -0,0 +1,371 @@
+package subcmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/chartfs"
+	"github.com/redhat-appstudio/tssc-cli/pkg/config"
+	"github.com/redhat-appstudio/tssc-cli/pkg/constants"
+	"github.com/redhat-appstudio/tssc-cli/pkg/flags"
+	"github.com/redhat-appstudio/tssc-cli/pkg/installer"
+	"github.com/redhat-appstudio/tssc-cli/pkg/k8s"
+	"github.com/redhat-appstudio/tssc-cli/pkg/resolver"
+
+	"github.com/redhat-appstudio/helmet/api"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Deploy represents the deploy subcommand, it installs or upgrades the Helm
+// charts resolved from the cluster configuration, in the topology order.
+type Deploy struct {
+	cmd    *cobra.Command    // cobra command
+	appCtx *api.AppContext   // application context
+	cfs    chartfs.Interface // installer filesystem
+	flags  *flags.Flags      // global flags
+	logger *slog.Logger      // application logger
+
+	kube             k8s.Interface             // kubernetes client
+	manager          *config.ConfigMapManager  // cluster configuration manager
+	cfg              *config.Config            // installer configuration
+	topologyBuilder  *resolver.TopologyBuilder // topology builder
+	integrationNames []string                  // known integration names
+
+	chartPath          string // single chart path
+	valuesTemplatePath string // values template file path
+	parallelism        int    // concurrent chart deployments
+	resume             bool   // skips charts checkpointed with same inputs
+	diff               bool   // compares the manifests, without deploying
+	diffLive           bool   // compares with the live objects as well
+	plan               bool   // shows the release plans, without deploying
+	forceUpgrade       bool   // upgrades releases already up to date
+	recoverPending     bool   // recovers pending releases regardless of age
+}
+
+var _ api.SubCommand = (*Deploy)(nil)
+
+const deployDesc = `
+Deploys the %[1]s platform components.
+
+The installer looks at the configuration to identify the products to be
+installed, and the dependencies to be resolved. The configuration is validated
+against the settings and product properties schemas beforehand.
+
+The platform configuration is rendered from the values template file
+(--values-template), this configuration payload is given to all Helm charts.
+Secret references ("secretRef") and environment variable references
+("${env:VAR}") on the configuration are resolved while rendering, the cluster
+configuration only holds the references.
+
+The installer resources are embedded in the executable, these resources are
+employed by default.
+
+The charts are deployed one at a time, in the topology order. With
+"--parallelism" greater than one, the charts are deployed by levels of the
+dependency graph, as shown by "%[1]s topology --output json": charts on the same
+level don't depend on each other and are deployed concurrently, up to the
+informed limit, with the output lines prefixed by the chart name. A level starts
+once the previous one is deployed, and the deployment stops on the first level
+with failed charts.
+
+The progress is checkpointed in the cluster, each chart successfully deployed
+is recorded with the digests of the chart and its rendered values. When the
+deployment fails, "--resume" continues from the failure point: the charts
+already deployed with identical inputs are skipped, the remaining ones are
+deployed. A complete deployment removes the checkpoints, and a new deployment
+without "--resume" starts over.
+
+When a chart fails to deploy, or its release fails verification, the failure
+policy decides what happens to the release: "leave" keeps it as is, "rollback"
+rolls it back to the last successfully deployed revision, and
+"uninstall-if-first-install" removes releases installed for the first time and
+rolls back the others. The policy is set globally by the "failurePolicy"
+setting, and per chart by the "failure-policy" annotation, "leave" is the
+default. Releases left pending by an interrupted deployment are recovered before
+the next deployment, once pending for longer than the timeout; before that, the
+release may still be deployed by another process and the deployment fails. Use
+"--recover-pending" to recover them regardless.
+
+Releases already up to date are skipped: the latest revision was deployed
+successfully by this installation, with the same chart version, rendered values
+digest and manifests, its tests didn't fail and its last deployment isn't
+recorded as failed. Therefore, charts without changes are neither upgraded nor
+tested again, use "--force-upgrade" to upgrade them regardless. With "--plan"
+nothing is deployed, the action for each chart, install, upgrade, no-op or
+blocked, is shown with its reason. Blocked releases belong to another
+installation, or are pending within the timeout, and fail the deployment.
+
+With "--diff" nothing is deployed, each chart is rendered with the current
+values, as "%[1]s template" does, and compared with the manifest of the deployed
+release. The changes are printed as unified diffs per resource, followed by the
+number of resources added, changed and removed per chart. With "--diff-live"
+the rendered resources are compared with the live objects as well, only the
+fields managed by the chart are shown. Secret values are always redacted.
+
+A single chart can be deployed by specifying its path. E.g.:
+
+	$ %[1]s deploy charts/%[2]s-openshift
+	$ %[1]s deploy --parallelism 3
+	$ %[1]s deploy --resume
+	$ %[1]s deploy --plan
+	$ %[1]s deploy --force-upgrade
+	$ %[1]s deploy --diff
+	$ %[1]s deploy --diff --diff-live charts/%[2]s-openshift
+`
+
+// Cmd exposes the cobra instance.
+func (d *Deploy) Cmd() *cobra.Command {
+	return d.cmd
+}
+
+// logWith decorates the informed logger with contextual information.
+func (d *Deploy) logWith(l *slog.Logger) *slog.Logger {
+	return d.flags.LoggerWith(l.With(
+		"chart-path", d.chartPath,
+		constants.ValuesTemplateFlag, d.valuesTemplatePath,
+	))
+}
+
+// log logger with contextual information.
+func (d *Deploy) log() *slog.Logger {
+	return d.logWith(d.logger)
+}
+
+// PersistentFlags injects the sub-command flags.
+func (d *Deploy) PersistentFlags(p *pflag.FlagSet) {
+	p.StringVar(
+		&d.valuesTemplatePath,
+		constants.ValuesTemplateFlag,
+		constants.ValuesFilename,
+		"Path to the values template file",
+	)
+	p.IntVar(
+		&d.parallelism,
+		"parallelism",
+		d.parallelism,
+		"Maximum number of independent charts deployed concurrently",
+	)
+	p.BoolVar(
+		&d.resume,
+		"resume",
+		false,
+		"Skips the charts deployed with identical inputs by the previous "+
+			"unfinished deployment",
+	)
+	p.BoolVar(
+		&d.diff,
+		"diff",
+		false,
+		"Shows the manifest changes against the deployed releases, without "+
+			"deploying",
+	)
+	p.BoolVar(
+		&d.diffLive,
+		"diff-live",
+		false,
+		"Compares the manifests with the live objects as well, requires --diff",
+	)
+	p.BoolVar(
+		&d.plan,
+		"plan",
+		false,
+		"Shows whether each chart would be installed, upgraded or skipped, "+
+			"without deploying",
+	)
+	p.BoolVar(
+		&d.forceUpgrade,
+		"force-upgrade",
+		false,
+		"Upgrades the releases already up to date, instead of skipping them",
+	)
+	p.BoolVar(
+		&d.recoverPending,
+		"recover-pending",
+		false,
+		"Recovers the releases left pending, even if changed within the timeout",
+	)
+}
+
+// Complete loads the cluster configuration and the charts collection.
+func (d *Deploy) Complete(args []string) error {
+	var err error
+	if d.flags, err = flags.NewFlagsFromCommand(d.cmd); err != nil {
+		return err
+	}
+	// Comparing the manifests, or planning, never changes the cluster.
+	if d.diff || d.plan {
+		d.flags.DryRun = true
+	}
+	d.logger = d.flags.GetLogger(os.Stdout)
+	d.kube = k8s.NewKube(d.flags)
+
+	if d.topologyBuilder, err = resolver.NewTopologyBuilder(
+		d.appCtx, d.logger, d.cfs, d.kube, d.integrationNames,
+	); err != nil {
+		return err
+	}
+	d.manager = config.NewConfigMapManager(
+		d.kube, d.appCtx.Name, d.flags.Instance)
+	if d.cfg, err = bootstrapConfig(
+		d.cmd.Context(), d.appCtx, d.manager,
+	); err != nil {
+		return err
+	}
+	if len(args) == 1 {
+		d.chartPath = args[0]
+	}
+	return nil
+}
+
+// Validate asserts the cluster configuration complies with the schemas before
+// any Helm chart is deployed.
+func (d *Deploy) Validate() error {
+	if d.topologyBuilder == nil {
+		panic("topology is nil")
+	}
+	if d.parallelism < 1 {
+		return fmt.Errorf("invalid --parallelism %d, must be at least 1",
+			d.parallelism)
+	}
+	if d.diffLive && !d.diff {
+		return fmt.Errorf("--diff-live requires --diff")
+	}
+	if d.diff && d.plan {
+		return fmt.Errorf("--diff and --plan are mutually exclusive")
+	}
+	if (d.diff || d.plan) && d.resume {
+		return fmt.Errorf("--resume can't be combined with --diff or --plan")
+	}
+	if err := validateSchema(d.cfs, d.cfg); err != nil {
+		return fmt.Errorf("ConfigMap %s/%s: %w",
+			d.cfg.Namespace(), d.manager.Name(), err)
+	}
+	return nil
+}
+
+// Run deploys the enabled dependencies listed on the configuration.
+func (d *Deploy) Run() error {
+	d.log().Debug("Reading values template file")
+	valuesTmpl, err := d.cfs.ReadFile(d.valuesTemplatePath)
+	if err != nil {
+		return err
+	}
+
+	ctx := d.cmd.Context()
+	topology, err := d.topologyBuilder.Build(ctx, d.cfg)
+	if err != nil {
+		return missingIntegrationsHint(d.appCtx, err)
+	}
+
+	// Dependencies organized in levels, deployed one after another. Without
+	// parallelism, each level holds a single dependency in topology order.
+	var levels []resolver.Dependencies
+	if d.chartPath == "" {
+		d.log().Debug("Installing all dependencies...",
+			"parallelism", d.parallelism)
+		levels = deploymentLevels(topology, d.parallelism)
+	} else {
+		d.log().Debug("Installing a single Helm chart...")
+		hc, err := d.cfs.GetChartFiles(d.chartPath)
+		if err != nil {
+			return err
+		}
+		dep, err := topology.GetDependency(hc.Name())
+		if err != nil {
+			return err
+		}
+		levels = append(levels, resolver.Dependencies{*dep})
+	}
+
+	deployment := installer.NewDeployment(
+		d.log(), d.flags, d.kube, d.appCtx.Name, d.cfg, string(valuesTmpl))
+	deployment.SetParallelism(d.parallelism)
+	deployment.SetResume(d.resume)
+	deployment.SetDiff(d.diff, d.diffLive)
+	deployment.SetPlan(d.plan)
+	deployment.SetForceUpgrade(d.forceUpgrade)
+	deployment.SetRecoverPending(d.recoverPending)
+	// The helm release timestamps have a second precision on some storage
+	// drivers, therefore the instant is truncated.
+	since := time.Now().Truncate(time.Second)
+	err = deployment.Run(ctx, topology, levels)
+	// Comparing the manifests, or planning, doesn't deploy anything.
+	if d.flags.DryRun || d.diff || d.plan {
+		return err
+	}
+	// The whole topology is deployed when no chart is informed, only then the
+	// installer version is recorded.
+	if recordErr := NewDeployRecorder(d.appCtx, d.cfs).record(
+		ctx, d.flags, since, err == nil && d.chartPath == "",
+		deployment.Failures(),
+	); recordErr != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: unable to record the deployment "+
+			"status: %v\n", recordErr)
+	}
+	return err
+}
+
+// missingIntegrationsHint decorates the topology error with instructions to
+// configure the missing integrations, other errors are returned as is.
+func missingIntegrationsHint(appCtx *api.AppContext, err error) error {
+	if !errors.Is(err, resolver.ErrMissingIntegrations) &&
+		!errors.Is(err, resolver.ErrPrerequisiteIntegration) {
+		return err
+	}
+	return fmt.Errorf(`%w
+
+Required integrations are missing from the cluster, run the "%s integration"
+subcommand to configure them. For example:
+
+	$ %s integration --help
+	$ %s integration <name> --help
+	`,
+		err, appCtx.Name, appCtx.Name, appCtx.Name)
+}
+
+// deploymentLevels organizes the topology dependencies in levels, with
+// parallelism the levels of the dependency graph, otherwise a single dependency
+// per level in topology order.
+func deploymentLevels(
+	topology *resolver.Topology,
+	parallelism int,
+) []resolver.Dependencies {
+	if parallelism > 1 {
+		return topology.Levels()
+	}
+	levels := []resolver.Dependencies{}
+	for _, dep := range topology.Dependencies() {
+		levels = append(levels, resolver.Dependencies{dep})
+	}
+	return levels
+}
+
+// NewDeploy instantiates the deploy subcommand, the integration names are the
+// integrations known by the installer, required by the Helm charts.
+func NewDeploy(
+	appCtx *api.AppContext,
+	cfs chartfs.Interface,
+	integrationNames []string,
+) *Deploy {
+	d := &Deploy{
+		cmd: &cobra.Command{
+			Use:   "deploy [chart]",
+			Short: fmt.Sprintf("Rollout %s platform components", appCtx.Name),
+			Long: fmt.Sprintf(
+				deployDesc, appCtx.Name, appCtx.IdentifierName()),
+			Args:         cobra.MaximumNArgs(1),
+			SilenceUsage: true,
+		},
+		appCtx:           appCtx,
+		cfs:              cfs,
+		logger:           slog.Default(),
+		integrationNames: integrationNames,
+		parallelism:      1,
+	}
+	d.PersistentFlags(d.cmd.PersistentFlags())
+	return d
+}
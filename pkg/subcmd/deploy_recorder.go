@@ -0,0 +1,109 @@
+package subcmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/chartfs"
+	"github.com/redhat-appstudio/tssc-cli/pkg/config"
+	"github.com/redhat-appstudio/tssc-cli/pkg/deployer"
+	"github.com/redhat-appstudio/tssc-cli/pkg/flags"
+	"github.com/redhat-appstudio/tssc-cli/pkg/k8s"
+	"github.com/redhat-appstudio/tssc-cli/pkg/status"
+
+	"github.com/redhat-appstudio/helmet/api"
+)
+
+// DeployRecorder records the state of the deployed Helm releases in the cluster
+// once the deployment finishes, used by the deploy and upgrade subcommands.
+type DeployRecorder struct {
+	appCtx *api.AppContext   // application context
+	cfs    chartfs.Interface // installer filesystem
+}
+
+// record records the state of the Helm releases deployed since the informed
+// instant, and when complete the installer version as well. The charts which
+// failed to deploy are recorded as such, with their errors, even when the
+// failure policy left a deployed release. The recorded state is printed out.
+func (d *DeployRecorder) record(
+	ctx context.Context,
+	f *flags.Flags,
+	since time.Time,
+	complete bool,
+	failures map[string]error,
+) error {
+	logger := f.LoggerWith(f.GetLogger(os.Stdout).With("type", "status"))
+	kube := k8s.NewKube(f)
+	cfg, err := config.NewConfigMapManager(
+		kube, d.appCtx.Name, f.Instance,
+	).GetConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	charts, err := d.cfs.GetAllCharts()
+	if err != nil {
+		return err
+	}
+	embedded := map[string]bool{}
+	for _, hc := range charts {
+		embedded[hc.Name()] = true
+	}
+
+	releases, err := deployer.NewReleases(
+		logger, f, kube, "", cfg.Namespace())
+	if err != nil {
+		return err
+	}
+	deployed, err := releases.List()
+	if err != nil {
+		return err
+	}
+
+	// Only the releases touched by this deployment are recorded, the previous
+	// state is kept for the remaining charts.
+	s, err := status.NewManager(kube, d.appCtx.Name).Update(
+		ctx,
+		cfg.Namespace(),
+		func(s *status.Status) error {
+			for _, rel := range deployed {
+				if !embedded[rel.Name] || rel.Info == nil ||
+					rel.Info.LastDeployed.Time.Before(since) {
+					continue
+				}
+				logger.Debug("Recording release state", "release", rel.Name)
+				if err := s.RecordRelease(
+					rel, d.appCtx.Version, d.appCtx.CommitID,
+				); err != nil {
+					return err
+				}
+			}
+			for name, err := range failures {
+				s.RecordFailure(name, err)
+			}
+			if complete {
+				s.RecordInstaller(d.appCtx.Version, d.appCtx.CommitID)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%s\n# Deployment status\n%s\n\n",
+		"############################################################",
+		"############################################################")
+	s.Print(os.Stdout)
+	return nil
+}
+
+// NewDeployRecorder instantiates the DeployRecorder.
+func NewDeployRecorder(
+	appCtx *api.AppContext,
+	cfs chartfs.Interface,
+) *DeployRecorder {
+	return &DeployRecorder{appCtx: appCtx, cfs: cfs}
+}
@@ -0,0 +1,127 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/config"
+	"github.com/redhat-appstudio/tssc-cli/pkg/flags"
+	"github.com/redhat-appstudio/tssc-cli/pkg/k8s"
+
+	"github.com/redhat-appstudio/helmet/api"
+	"github.com/spf13/cobra"
+)
+
+// ErrCommandNotFound the framework subcommand is not registered on the root.
+var ErrCommandNotFound = errors.New("subcommand not found")
+
+// frameworkCommand looks up a subcommand registered by the framework on the root
+// command, by name.
+func frameworkCommand(root *cobra.Command, name string) (*cobra.Command, error) {
+	for _, c := range root.Commands() {
+		if c.Name() == name {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrCommandNotFound, name)
+}
+
+// bootstrapConfig loads the installer configuration from the cluster, printing
+// out instructions when it's not possible.
+func bootstrapConfig(
+	ctx context.Context,
+	appCtx *api.AppContext,
+	manager *config.ConfigMapManager,
+) (*config.Config, error) {
+	cfg, err := manager.GetConfig(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, `
+Unable to find the configuration in the cluster, or the configuration is invalid.
+Please refer to the subcommand "%s config" to manage installer's
+configuration for the target cluster.
+
+	$ %s config --help
+		`, appCtx.Name, appCtx.Name)
+	}
+	return cfg, err
+}
+
+// author returns the configuration revision author for the informed command,
+// composed by the source of the change and the subcommand path. The command line
+// running inside a Pod is considered a job.
+func author(cmd *cobra.Command) string {
+	source := config.AuthorCLI
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		source = config.AuthorJob
+	}
+	path := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	return fmt.Sprintf("%s: %s", source, path)
+}
+
+// recordConfig records the current cluster configuration as a new revision, the
+// recording failures are only printed out as warnings.
+func recordConfig(
+	ctx context.Context,
+	appCtx *api.AppContext,
+	logger *slog.Logger,
+	f *flags.Flags,
+	author string,
+	note string,
+	previous *config.Config,
+	current *config.Config,
+) {
+	history := config.NewHistoryManager(k8s.NewKube(f), appCtx.Name)
+	revision, err := history.Record(ctx, author, note, previous, current)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: unable to record the configuration "+
+			"revision: %v\n", err)
+		return
+	}
+	if revision != nil {
+		logger.Debug("Configuration revision recorded",
+			"revision", revision.Revision, "author", revision.Author)
+	}
+}
+
+// updateConfig stores the informed configuration in the cluster, recording the
+// change on the configuration history, the note prefixes the revision summary.
+// The configuration is validated by the manager, in dry-run mode the resulting
+// configuration is only validated and shown.
+func updateConfig(
+	cmd *cobra.Command,
+	appCtx *api.AppContext,
+	logger *slog.Logger,
+	f *flags.Flags,
+	manager *config.ConfigMapManager,
+	cfg *config.Config,
+	note string,
+) error {
+	if f.DryRun {
+		if err := manager.Validate(cfg); err != nil {
+			return err
+		}
+		logger.Info("Dry-run mode enabled, skipping configuration update")
+		fmt.Print(cfg.String())
+		return nil
+	}
+	ctx := cmd.Context()
+	// The configuration stored in the cluster is the previous revision, the
+	// informed configuration may have been changed in place.
+	previous, err := manager.GetConfig(ctx)
+	if err != nil {
+		logger.Debug("Unable to load the previous configuration", "error", err)
+		previous = nil
+	}
+	logger.Debug("Updating the cluster configuration")
+	if err = manager.Update(ctx, cfg); err != nil {
+		return err
+	}
+	fmt.Printf("Configuration updated on ConfigMap %s/%s.\n",
+		cfg.Namespace(), manager.Name())
+	recordConfig(ctx, appCtx, logger, f, author(cmd), note, previous, cfg)
+	return nil
+}
@@ -0,0 +1,84 @@
+package subcmd
+
+import (
+	"github.com/redhat-appstudio/tssc-cli/pkg/chartfs"
+	"github.com/redhat-appstudio/tssc-cli/pkg/flags"
+	"github.com/redhat-appstudio/tssc-cli/pkg/k8s"
+	"github.com/redhat-appstudio/tssc-cli/pkg/resolver"
+
+	"github.com/redhat-appstudio/helmet/api"
+	"github.com/spf13/cobra"
+)
+
+// IntegrationProduct decorates the framework integration subcommand, replacing
+// its persistent post-run: once the integration secret exists, the product which
+// provides the same integration is disabled in the cluster configuration. The
+// configuration is updated conditioned to the resource version loaded, so
+// concurrent changes are merged instead of overwritten, and validated against
+// the schemas.
+type IntegrationProduct struct {
+	appCtx *api.AppContext   // application context
+	cfs    chartfs.Interface // installer filesystem
+}
+
+// postRun disables the product providing the integration, the integration name
+// is the name of the nested subcommand executed.
+func (i *IntegrationProduct) postRun(cmd *cobra.Command, _ []string) error {
+	f, err := flags.NewFlagsFromCommand(cmd)
+	if err != nil {
+		return err
+	}
+	kube := k8s.NewKube(f)
+	manager, err := newConfigMapManager(i.appCtx, i.cfs, kube, f.Instance)
+	if err != nil {
+		return err
+	}
+	cfg, err := manager.GetConfig(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	exists, err := k8s.SecretExists(cmd.Context(), kube,
+		resolver.IntegrationSecretName(cfg, i.appCtx.Name, cmd.Name()))
+	if err != nil || !exists {
+		return err
+	}
+
+	charts, err := i.cfs.GetAllCharts()
+	if err != nil {
+		return err
+	}
+	collection, err := resolver.NewCollection(i.appCtx, charts)
+	if err != nil {
+		return err
+	}
+	productName := collection.GetProductNameForIntegration(cmd.Name())
+	if productName == "" {
+		return nil
+	}
+	spec, err := cfg.GetProduct(productName)
+	if err != nil {
+		return err
+	}
+	if !spec.Enabled {
+		return nil
+	}
+	spec.Enabled = false
+	if err = cfg.SetProduct(productName, *spec); err != nil {
+		return err
+	}
+	return manager.Update(cmd.Context(), cfg)
+}
+
+// Decorate replaces the informed subcommand persistent post-run.
+func (i *IntegrationProduct) Decorate(c *cobra.Command) {
+	c.PersistentPostRunE = i.postRun
+}
+
+// NewIntegrationProduct instantiates the IntegrationProduct.
+func NewIntegrationProduct(
+	appCtx *api.AppContext,
+	cfs chartfs.Interface,
+) *IntegrationProduct {
+	return &IntegrationProduct{appCtx: appCtx, cfs: cfs}
+}
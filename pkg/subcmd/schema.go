@@ -0,0 +1,36 @@
+package subcmd
+
+import (
+	"github.com/redhat-appstudio/tssc-cli/pkg/chartfs"
+	"github.com/redhat-appstudio/tssc-cli/pkg/config"
+	"github.com/redhat-appstudio/tssc-cli/pkg/k8s"
+
+	"github.com/redhat-appstudio/helmet/api"
+)
+
+// validateSchema validates the configuration against the settings schema and the
+// product properties schemas available on the installer filesystem.
+func validateSchema(cfs chartfs.Interface, cfg *config.Config) error {
+	schema, err := config.NewSchema(cfs)
+	if err != nil {
+		return err
+	}
+	return schema.Validate(cfg)
+}
+
+// newConfigMapManager instantiates the cluster configuration manager, the
+// configuration written by the manager is validated against the schemas.
+func newConfigMapManager(
+	appCtx *api.AppContext,
+	cfs chartfs.Interface,
+	kube k8s.Interface,
+	instance string,
+) (*config.ConfigMapManager, error) {
+	schema, err := config.NewSchema(cfs)
+	if err != nil {
+		return nil, err
+	}
+	manager := config.NewConfigMapManager(kube, appCtx.Name, instance)
+	manager.SetSchema(schema)
+	return manager, nil
+}
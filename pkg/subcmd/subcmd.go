@@ -0,0 +1,114 @@
+package subcmd
+
+import (
+	"github.com/redhat-appstudio/tssc-cli/pkg/chartfs"
+	"github.com/redhat-appstudio/tssc-cli/pkg/flags"
+
+	"github.com/redhat-appstudio/helmet/api"
+	"github.com/spf13/cobra"
+)
+
+// Decorator extends a subcommand provided by the framework, wrapping its
+// lifecycle with installer specific behavior.
+type Decorator interface {
+	// Decorate wraps the informed framework subcommand.
+	Decorate(*cobra.Command)
+}
+
+// Setup registers the installer specific subcommands on the root command, or
+// nested on framework subcommands, and decorates the subcommands provided by the
+// framework. The integration modules are the integrations registered on the
+// framework application.
+func Setup(
+	appCtx *api.AppContext,
+	cfs chartfs.Interface,
+	root *cobra.Command,
+	integrations []api.IntegrationModule,
+) error {
+	integrationNames := make([]string, 0, len(integrations))
+	for _, mod := range integrations {
+		integrationNames = append(integrationNames, mod.Name)
+	}
+
+	// Installation selector, the namespace of the installer configuration, for
+	// clusters with multiple independent installations. The installations must
+	// not share namespaces, enforced on deployment.
+	root.PersistentFlags().String(
+		flags.InstanceFlag,
+		"",
+		"installation namespace, required when multiple installations exist, "+
+			"each installation must use separate namespaces",
+	)
+
+	// Framework subcommands replaced by the installer implementation, which
+	// renders and deploys the Helm charts.
+	replacements := []api.SubCommand{
+		NewDeploy(appCtx, cfs, integrationNames),
+		NewTemplate(appCtx, cfs),
+		NewTopology(appCtx, cfs, integrationNames),
+	}
+	for _, sub := range replacements {
+		c := api.NewRunner(sub).Cmd()
+		if existing, err := frameworkCommand(root, c.Name()); err == nil {
+			root.RemoveCommand(existing)
+		}
+		root.AddCommand(c)
+	}
+
+	// Decorators applied on subcommands, by name, in order.
+	decorators := map[string][]Decorator{
+		"config": {
+			NewConfigCreate(appCtx, cfs),
+			NewConfigRecorder(appCtx),
+		},
+		"integration": {
+			NewIntegrationProduct(appCtx, cfs),
+			NewConfigRecorder(appCtx),
+		},
+		"mcp-server": {
+			NewConfigWatcher(appCtx, cfs),
+		},
+	}
+	for name, ds := range decorators {
+		c, err := frameworkCommand(root, name)
+		if err != nil {
+			return err
+		}
+		for _, d := range ds {
+			d.Decorate(c)
+		}
+	}
+
+	// Subcommands nested on framework subcommands, by parent name.
+	children := map[string][]api.SubCommand{
+		"config": {
+			NewConfigSet(appCtx, cfs),
+			NewConfigUnset(appCtx, cfs),
+			NewConfigDiff(appCtx, cfs),
+			NewConfigHistory(appCtx),
+			NewConfigRollback(appCtx, cfs),
+		},
+		"topology": {
+			NewTopologyLint(appCtx, cfs, integrationNames),
+			NewTopologyRequirements(appCtx, cfs, integrationNames),
+		},
+	}
+	for name, subs := range children {
+		c, err := frameworkCommand(root, name)
+		if err != nil {
+			return err
+		}
+		for _, sub := range subs {
+			c.AddCommand(api.NewRunner(sub).Cmd())
+		}
+	}
+
+	subs := []api.SubCommand{
+		NewUpgrade(appCtx, cfs, integrationNames),
+		NewUninstall(appCtx, cfs, integrationNames),
+	}
+	for _, sub := range subs {
+		root.AddCommand(api.NewRunner(sub).Cmd())
+	}
+	return nil
+}
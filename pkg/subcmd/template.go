@@ -0,0 +1,177 @@
+package subcmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/chartfs"
+	"github.com/redhat-appstudio/tssc-cli/pkg/config"
+	"github.com/redhat-appstudio/tssc-cli/pkg/constants"
+	"github.com/redhat-appstudio/tssc-cli/pkg/flags"
+	"github.com/redhat-appstudio/tssc-cli/pkg/installer"
+	"github.com/redhat-appstudio/tssc-cli/pkg/k8s"
+	"github.com/redhat-appstudio/tssc-cli/pkg/resolver"
+
+	"github.com/redhat-appstudio/helmet/api"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Template represents the "template" subcommand.
+type Template struct {
+	cmd    *cobra.Command    // cobra command
+	appCtx *api.AppContext   // application context
+	cfs    chartfs.Interface // installer filesystem
+	flags  *flags.Flags      // global flags
+
+	kube k8s.Interface       // kubernetes client
+	cfg  *config.Config      // installer configuration
+	dep  resolver.Dependency // chart to render
+
+	valuesTemplatePath string // path to the values template file
+	showValues         bool   // show rendered values
+	showManifests      bool   // show rendered manifests
+	namespace          string // dependency namespace
+}
+
+var _ api.SubCommand = (*Template)(nil)
+
+const templateDesc = `
+The Template subcommand is used to render the values template file and,
+optionally, the Helm chart manifests. It is particularly useful for
+troubleshooting and developing Helm charts for the installation process.
+
+By using the '--show-manifests=false' flag, only the global values template
+('--values-template') will be rendered as YAML, thus the last argument, with the
+Helm chart directory, optional.
+
+Additionally, the '--debug' flag should be used to display rendered global values,
+passed into every Helm Chart installed, as key-value pairs.
+
+The configuration references, secrets and environment variables, are resolved
+on the rendered values, which may therefore contain sensitive data.
+
+The installer resources are embedded in the executable, these resources are
+employed by default, to use local files just use the last argument with the path
+to the local Helm Chart.
+
+Examples:
+
+  # Only showing the global values as YAML.
+  $ %[1]s template --show-manifests=false
+
+  # Rendering only the templates of a single Helm Chart.
+  $ %[1]s template --show-values=false charts/%[2]s-subscriptions
+
+  # Rendering all resources of a Helm Chart.
+  $ %[1]s template charts/%[2]s-subscriptions`
+
+// Cmd exposes the cobra instance.
+func (t *Template) Cmd() *cobra.Command {
+	return t.cmd
+}
+
+// PersistentFlags injects the sub-command flags.
+func (t *Template) PersistentFlags(p *pflag.FlagSet) {
+	p.StringVar(
+		&t.valuesTemplatePath,
+		constants.ValuesTemplateFlag,
+		constants.ValuesFilename,
+		"Path to the values template file",
+	)
+	p.StringVar(&t.namespace, "namespace", t.namespace,
+		"namespace to use on template rendering")
+	p.BoolVar(&t.showValues, "show-values", t.showValues,
+		"show values template rendered payload")
+	p.BoolVar(&t.showManifests, "show-manifests", t.showManifests,
+		"show Helm chart rendered manifests")
+}
+
+// Complete parse the informed args as charts, when valid.
+func (t *Template) Complete(args []string) error {
+	var err error
+	if t.flags, err = flags.NewFlagsFromCommand(t.cmd); err != nil {
+		return err
+	}
+	// Dry-run mode is always enabled for templating.
+	t.flags.DryRun = true
+	t.kube = k8s.NewKube(t.flags)
+
+	if len(args) == 1 {
+		hc, err := t.cfs.GetChartFiles(args[0])
+		if err != nil {
+			return err
+		}
+		t.dep = *resolver.NewDependencyWithNamespace(hc, t.namespace)
+	}
+
+	t.cfg, err = bootstrapConfig(
+		t.cmd.Context(),
+		t.appCtx,
+		config.NewConfigMapManager(t.kube, t.appCtx.Name, t.flags.Instance),
+	)
+	return err
+}
+
+// Validate asserts the chart is informed when the manifests are shown.
+func (t *Template) Validate() error {
+	if t.showManifests && t.dep.Chart() == nil {
+		return fmt.Errorf("missing chart path")
+	}
+	return nil
+}
+
+// Run Renders the templates.
+func (t *Template) Run() error {
+	valuesTmplPayload, err := t.cfs.ReadFile(t.valuesTemplatePath)
+	if err != nil {
+		return fmt.Errorf("failed to read values template file: %w", err)
+	}
+
+	logger := t.flags.GetLogger(os.Stdout)
+	i := installer.NewInstaller(
+		logger, os.Stdout, t.flags, t.kube, &t.dep)
+	if err = i.SetValues(
+		t.cmd.Context(),
+		t.cfg,
+		string(valuesTmplPayload),
+	); err != nil {
+		return err
+	}
+
+	// Rendering the global values.
+	if err = i.RenderValues(); err != nil {
+		return err
+	}
+	// Show the rendered global values, what's passed into very chart.
+	if t.showValues {
+		i.PrintRawValues()
+	}
+
+	// When the manifests aren't shown, we don't need to dry-run "helm install".
+	if !t.showManifests {
+		return nil
+	}
+	return i.Install(t.cmd.Context())
+}
+
+// NewTemplate creates the "template" subcommand with flags.
+func NewTemplate(appCtx *api.AppContext, cfs chartfs.Interface) *Template {
+	t := &Template{
+		cmd: &cobra.Command{
+			Use:   "template [chart]",
+			Short: "Render Helm chart templates",
+			Long: fmt.Sprintf(
+				templateDesc, appCtx.Name, appCtx.IdentifierName()),
+			Args:         cobra.MaximumNArgs(1),
+			SilenceUsage: true,
+		},
+		appCtx:        appCtx,
+		cfs:           cfs,
+		showValues:    true,
+		showManifests: true,
+		namespace:     "default",
+	}
+	t.PersistentFlags(t.cmd.PersistentFlags())
+	return t
+}
@@ -0,0 +1,264 @@
+package subcmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/chartfs"
+	"github.com/redhat-appstudio/tssc-cli/pkg/config"
+	"github.com/redhat-appstudio/tssc-cli/pkg/flags"
+	"github.com/redhat-appstudio/tssc-cli/pkg/k8s"
+	"github.com/redhat-appstudio/tssc-cli/pkg/resolver"
+	"github.com/redhat-appstudio/tssc-cli/pkg/status"
+
+	"github.com/redhat-appstudio/helmet/api"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Topology represents the topology subcommand, it reports the installer
+// dependency topology based on the cluster configuration and Helm charts, next
+// to the deployment state recorded in the cluster.
+type Topology struct {
+	cmd    *cobra.Command    // cobra command
+	appCtx *api.AppContext   // application context
+	cfs    chartfs.Interface // installer filesystem
+	flags  *flags.Flags      // global flags
+
+	kube             k8s.Interface        // kubernetes client
+	vars             *resolver.Context    // CEL context variables
+	collection       *resolver.Collection // chart collection
+	cfg              *config.Config       // installer configuration
+	integrationNames []string             // known integration names
+
+	output string // output format
+	why    string // chart to explain
+}
+
+var _ api.SubCommand = (*Topology)(nil)
+
+const (
+	// topologyOutputTable human readable table output.
+	topologyOutputTable = "table"
+	// topologyOutputJSON machine readable JSON output.
+	topologyOutputJSON = "json"
+	// topologyOutputYAML machine readable YAML output.
+	topologyOutputYAML = "yaml"
+	// topologyOutputDOT Graphviz DOT graph output.
+	topologyOutputDOT = "dot"
+	// topologyOutputMermaid Mermaid flowchart output.
+	topologyOutputMermaid = "mermaid"
+)
+
+const topologyDesc = `
+Report the dependency topology of the installer based on the cluster configuration
+and Helm charts. It will output a table with the following columns:
+
+  - Index: the index of the chart in the dependency graph.
+  - Dependency: the name of the Helm chart.
+  - Namespace: the OpenShift namespace where the chart is installed.
+  - Product: the name of the product the chart is associated with.
+  - Depends-On: comma-separated list of charts the chart depends on.
+  - Soft-Depends-On: charts deployed before the chart when they are part of the
+    topology, without requiring them.
+  - Provided-Integrations: comma-separated integrations provided by the chart.
+  - Required-Integrations: CEL expressions with the required integrations.
+
+The deployment status recorded in the cluster is shown afterwards.
+
+The "--output" flag selects a machine readable format instead, "json" and "yaml"
+describe the dependencies in deployment order with their deployment level (see
+"deploy --parallelism"), the depends-on edges, and the required integrations CEL
+expressions with their evaluation result against the integrations configured in
+the cluster. The "dot" (Graphviz) and "mermaid"
+formats render the dependency graph, edges point from a chart to the charts it
+depends on, soft dependencies are dashed, and charts with unsatisfied
+integrations are highlighted.
+
+The "--why" flag explains a single chart instead: why it's part of the topology,
+or why it's excluded, which rule chose its namespace, and which depends-on edges
+and weights determine its position. Only the "table", "json" and "yaml" formats
+are supported for explanations.
+
+Examples:
+
+	$ %[1]s topology
+	$ %[1]s topology --output json
+	$ %[1]s topology --output dot | dot -Tsvg > topology.svg
+	$ %[1]s topology --why %[2]s-integrations
+`
+
+// Cmd exposes the cobra instance.
+func (t *Topology) Cmd() *cobra.Command {
+	return t.cmd
+}
+
+// PersistentFlags injects the sub-command flags.
+func (t *Topology) PersistentFlags(p *pflag.FlagSet) {
+	p.StringVarP(
+		&t.output,
+		"output",
+		"o",
+		topologyOutputTable,
+		fmt.Sprintf("Output format (%s)", strings.Join([]string{
+			topologyOutputTable,
+			topologyOutputJSON,
+			topologyOutputYAML,
+			topologyOutputDOT,
+			topologyOutputMermaid,
+		}, ", ")),
+	)
+	p.StringVar(&t.why, "why", "",
+		"Explains why the informed chart is, or isn't, part of the topology")
+}
+
+// Complete instantiates the cluster configuration and charts.
+func (t *Topology) Complete(_ []string) error {
+	var err error
+	if t.flags, err = flags.NewFlagsFromCommand(t.cmd); err != nil {
+		return err
+	}
+	t.kube = k8s.NewKube(t.flags)
+
+	charts, err := t.cfs.GetAllCharts()
+	if err != nil {
+		return err
+	}
+	if t.collection, err = resolver.NewCollection(t.appCtx, charts); err != nil {
+		return err
+	}
+	t.cfg, err = bootstrapConfig(
+		t.cmd.Context(),
+		t.appCtx,
+		config.NewConfigMapManager(t.kube, t.appCtx.Name, t.flags.Instance),
+	)
+	return err
+}
+
+// Validate asserts the output format is supported.
+func (t *Topology) Validate() error {
+	switch t.output {
+	case topologyOutputTable, topologyOutputJSON, topologyOutputYAML:
+		return nil
+	case topologyOutputDOT, topologyOutputMermaid:
+		if t.why != "" {
+			return fmt.Errorf(
+				"output format %q is not supported with --why", t.output)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format %q", t.output)
+	}
+}
+
+// printExplanation explains why the chart is, or isn't, part of the resolved
+// topology, on the selected output format.
+func (t *Topology) printExplanation(r *resolver.Resolver) error {
+	e, err := r.Explain(t.why)
+	if err != nil {
+		return err
+	}
+	switch t.output {
+	case topologyOutputJSON:
+		return e.PrintJSON(os.Stdout)
+	case topologyOutputYAML:
+		return e.PrintYAML(os.Stdout)
+	default:
+		e.PrintText(os.Stdout)
+		return nil
+	}
+}
+
+// printStatus prints the recorded deployment state, when any.
+func (t *Topology) printStatus() error {
+	s, err := status.NewManager(t.kube, t.appCtx.Name).
+		Get(t.cmd.Context(), t.cfg.Namespace())
+	if err != nil {
+		return err
+	}
+	if s.IsEmpty() {
+		fmt.Printf("\nNo deployment recorded in the cluster.\n")
+		return nil
+	}
+	fmt.Printf("\nDeployment status:\n\n")
+	s.Print(os.Stdout)
+	return nil
+}
+
+// printReport prints the topology report on the selected output format, the
+// required integrations are evaluated against the cluster integrations.
+func (t *Topology) printReport(topology *resolver.Topology) error {
+	i, err := resolver.NewIntegrations(
+		t.cmd.Context(),
+		t.kube,
+		t.cfg,
+		t.vars,
+		t.appCtx.Name,
+		t.integrationNames,
+	)
+	if err != nil {
+		return err
+	}
+	requirements, err := i.Requirements(topology)
+	if err != nil {
+		return err
+	}
+	report := resolver.NewReport(topology, requirements)
+	switch t.output {
+	case topologyOutputJSON:
+		return report.PrintJSON(os.Stdout)
+	case topologyOutputYAML:
+		return report.PrintYAML(os.Stdout)
+	case topologyOutputDOT:
+		report.PrintDOT(os.Stdout)
+	case topologyOutputMermaid:
+		report.PrintMermaid(os.Stdout)
+	}
+	return nil
+}
+
+// Run resolves the dependency graph.
+func (t *Topology) Run() error {
+	// Resolving the dependency topology based on the installer configuration and
+	// Helm charts.
+	topology := resolver.NewTopology()
+	t.vars = resolver.NewContextFromCluster(t.cmd.Context(), t.kube, t.cfg)
+	r := resolver.NewResolver(t.cfg, t.collection, topology, t.vars)
+	if err := r.Resolve(); err != nil {
+		return err
+	}
+	if t.why != "" {
+		return t.printExplanation(r)
+	}
+	if t.output != topologyOutputTable {
+		return t.printReport(topology)
+	}
+	// Printing the resolved dependency to the standard output.
+	r.Print(os.Stdout)
+	return t.printStatus()
+}
+
+// NewTopology instantiates a new Topology subcommand, the integration names are
+// the integrations known by the installer, required by the Helm charts.
+func NewTopology(
+	appCtx *api.AppContext,
+	cfs chartfs.Interface,
+	integrationNames []string,
+) *Topology {
+	t := &Topology{
+		cmd: &cobra.Command{
+			Use:   "topology",
+			Short: "Shows the installer topology",
+			Long: fmt.Sprintf(
+				topologyDesc, appCtx.Name, appCtx.IdentifierName()),
+			Args:         cobra.NoArgs,
+			SilenceUsage: true,
+		},
+		appCtx:           appCtx,
+		cfs:              cfs,
+		integrationNames: integrationNames,
+	}
+	t.PersistentFlags(t.cmd.PersistentFlags())
+	return t
+}
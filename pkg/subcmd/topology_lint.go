@@ -0,0 +1,155 @@
+package subcmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/chartfs"
+	"github.com/redhat-appstudio/tssc-cli/pkg/config"
+	"github.com/redhat-appstudio/tssc-cli/pkg/flags"
+	"github.com/redhat-appstudio/tssc-cli/pkg/resolver"
+
+	"github.com/redhat-appstudio/helmet/api"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// TopologyLint represents the "topology lint" subcommand, it inspects the Helm
+// charts annotations and the configuration for inconsistencies.
+type TopologyLint struct {
+	cmd              *cobra.Command    // cobra command
+	appCtx           *api.AppContext   // application context
+	cfs              chartfs.Interface // installer filesystem
+	flags            *flags.Flags      // global flags
+	logger           *slog.Logger      // application logger
+	integrationNames []string          // known integration names
+
+	configPath string // configuration file path
+	output     string // output format
+}
+
+var _ api.SubCommand = (*TopologyLint)(nil)
+
+const topologyLintDesc = `
+Inspects the installer Helm charts annotations, and the configuration file,
+reporting the issues found with their file location:
+
+  - "depends-on" and "soft-depends-on" charts which don't exist, circular
+    dependencies, and version constraints not satisfied by the charts.
+  - Unknown integration names in "integrations-provided".
+  - Invalid CEL expressions in "integrations-required", including unknown
+    integration names, and in "enabled-if" and "requires-cluster".
+  - Malformed "requires-apis" and "provides-apis" entries, and chart CRDs.
+  - Invalid "weight" and "failure-policy" values.
+  - "use-product-namespace" and "product-name" products missing from the
+    configuration, and configuration products without a chart.
+  - Duplicated chart and product names.
+
+The cluster is not accessed. The subcommand exits with non-zero status when
+issues are found, so it can be used on CI pipelines.
+
+Examples:
+
+	$ %[1]s topology lint
+	$ %[1]s topology lint --output json
+	$ %[1]s topology lint --config config.yaml
+`
+
+// Cmd exposes the cobra instance.
+func (t *TopologyLint) Cmd() *cobra.Command {
+	return t.cmd
+}
+
+// log returns a decorated logger.
+func (t *TopologyLint) log() *slog.Logger {
+	return t.flags.LoggerWith(t.logger.With("config-path", t.configPath))
+}
+
+// PersistentFlags injects the sub-command flags.
+func (t *TopologyLint) PersistentFlags(p *pflag.FlagSet) {
+	p.StringVar(
+		&t.configPath,
+		"config",
+		config.DefaultRelativeConfigPath,
+		"Configuration file, uses the embedded configuration by default",
+	)
+	p.StringVarP(
+		&t.output,
+		"output",
+		"o",
+		diffOutputText,
+		fmt.Sprintf("Output format (%s, %s)", diffOutputText, diffOutputJSON),
+	)
+}
+
+// Complete instantiates the global flags.
+func (t *TopologyLint) Complete(_ []string) error {
+	var err error
+	if t.flags, err = flags.NewFlagsFromCommand(t.cmd); err != nil {
+		return err
+	}
+	t.logger = t.flags.GetLogger(os.Stdout)
+	return nil
+}
+
+// Validate asserts the output format is supported.
+func (t *TopologyLint) Validate() error {
+	switch t.output {
+	case diffOutputText, diffOutputJSON:
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format %q", t.output)
+	}
+}
+
+// Run lints the charts and configuration, printing the findings.
+func (t *TopologyLint) Run() error {
+	t.log().Debug("Linting the Helm charts and configuration")
+	findings, err := resolver.NewLinter(
+		t.cfs, t.configPath, t.appCtx.Name, t.integrationNames,
+	).Lint()
+	if err != nil {
+		return err
+	}
+
+	if t.output == diffOutputJSON {
+		if err = resolver.PrintFindingsJSON(os.Stdout, findings); err != nil {
+			return err
+		}
+	} else if len(findings) > 0 {
+		resolver.PrintFindings(os.Stdout, findings)
+	} else {
+		fmt.Printf("No issues found.\n")
+	}
+
+	if len(findings) > 0 {
+		return fmt.Errorf("%w: %d issue(s)",
+			resolver.ErrLintFindings, len(findings))
+	}
+	return nil
+}
+
+// NewTopologyLint instantiates the "topology lint" subcommand, the integration
+// names are the integrations known by the installer.
+func NewTopologyLint(
+	appCtx *api.AppContext,
+	cfs chartfs.Interface,
+	integrationNames []string,
+) *TopologyLint {
+	t := &TopologyLint{
+		cmd: &cobra.Command{
+			Use:          "lint",
+			Short:        "Inspects the Helm charts annotations",
+			Long:         fmt.Sprintf(topologyLintDesc, appCtx.Name),
+			Args:         cobra.NoArgs,
+			SilenceUsage: true,
+		},
+		appCtx:           appCtx,
+		cfs:              cfs,
+		logger:           slog.Default(),
+		integrationNames: integrationNames,
+	}
+	t.PersistentFlags(t.cmd.PersistentFlags())
+	return t
+}
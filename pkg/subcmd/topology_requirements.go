@@ -0,0 +1,191 @@
+package subcmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/chartfs"
+	"github.com/redhat-appstudio/tssc-cli/pkg/config"
+	"github.com/redhat-appstudio/tssc-cli/pkg/flags"
+	"github.com/redhat-appstudio/tssc-cli/pkg/k8s"
+	"github.com/redhat-appstudio/tssc-cli/pkg/resolver"
+
+	"github.com/redhat-appstudio/helmet/api"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// TopologyRequirements represents the "topology requirements" subcommand, it
+// solves the integrations required by the topology, proposing the minimal sets
+// of integrations to configure.
+type TopologyRequirements struct {
+	cmd              *cobra.Command    // cobra command
+	appCtx           *api.AppContext   // application context
+	cfs              chartfs.Interface // installer filesystem
+	flags            *flags.Flags      // global flags
+	integrationNames []string          // known integration names
+
+	kube       k8s.Interface        // kubernetes client
+	collection *resolver.Collection // chart collection
+	cfg        *config.Config       // installer configuration
+
+	output string // output format
+}
+
+var _ api.SubCommand = (*TopologyRequirements)(nil)
+
+const topologyRequirementsDesc = `
+Solves the combined integrations requirements of the topology, the CEL
+expressions on the "integrations-required" chart annotations, against the
+integrations configured in the cluster and the ones provided by the charts.
+
+When the requirements are not satisfied, the minimal alternative sets of missing
+integrations satisfying all of them are listed, in order of preference: the sets
+with fewer integrations to configure manually first, integrations provided by
+enabling a product are preferred. A product is only proposed when, once enabled,
+its charts resolve and their own integrations and cluster requirements are met.
+Each integration comes with the command scaffold to configure it, fill in the
+placeholders before running it.
+
+Examples:
+
+	$ %[1]s topology requirements
+	$ %[1]s topology requirements --output json
+`
+
+// Cmd exposes the cobra instance.
+func (t *TopologyRequirements) Cmd() *cobra.Command {
+	return t.cmd
+}
+
+// PersistentFlags injects the sub-command flags.
+func (t *TopologyRequirements) PersistentFlags(p *pflag.FlagSet) {
+	p.StringVarP(
+		&t.output,
+		"output",
+		"o",
+		diffOutputText,
+		fmt.Sprintf("Output format (%s, %s)", diffOutputText, diffOutputJSON),
+	)
+}
+
+// Complete instantiates the cluster configuration and charts.
+func (t *TopologyRequirements) Complete(_ []string) error {
+	var err error
+	if t.flags, err = flags.NewFlagsFromCommand(t.cmd); err != nil {
+		return err
+	}
+	t.kube = k8s.NewKube(t.flags)
+
+	charts, err := t.cfs.GetAllCharts()
+	if err != nil {
+		return err
+	}
+	if t.collection, err = resolver.NewCollection(t.appCtx, charts); err != nil {
+		return err
+	}
+	t.cfg, err = bootstrapConfig(
+		t.cmd.Context(),
+		t.appCtx,
+		config.NewConfigMapManager(t.kube, t.appCtx.Name, t.flags.Instance),
+	)
+	return err
+}
+
+// Validate asserts the output format is supported.
+func (t *TopologyRequirements) Validate() error {
+	switch t.output {
+	case diffOutputText, diffOutputJSON:
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format %q", t.output)
+	}
+}
+
+// scaffold returns the command line to configure the informed integration, based
+// on its "integration" subcommand required arguments and flags.
+func (t *TopologyRequirements) scaffold(name string) string {
+	c, _, err := t.cmd.Root().Find([]string{"integration", name})
+	if err != nil || c.Name() != name {
+		return fmt.Sprintf("%s integration %s", t.appCtx.Name, name)
+	}
+	line := []string{c.CommandPath()}
+	// Required positional arguments, the optional ones in brackets are omitted.
+	for _, arg := range strings.Fields(c.Use)[1:] {
+		if strings.HasPrefix(arg, "<") {
+			line = append(line, arg)
+		}
+	}
+	c.NonInheritedFlags().VisitAll(func(f *pflag.Flag) {
+		if _, required := f.Annotations[cobra.BashCompOneRequiredFlag]; !required {
+			return
+		}
+		if f.Value.Type() == "bool" {
+			line = append(line, "--"+f.Name)
+			return
+		}
+		line = append(line, fmt.Sprintf("--%s=%q", f.Name, "<"+f.Name+">"))
+	})
+	return strings.Join(line, " ")
+}
+
+// Run resolves the topology and solves its integrations requirements.
+func (t *TopologyRequirements) Run() error {
+	topology := resolver.NewTopology()
+	vars := resolver.NewContextFromCluster(t.cmd.Context(), t.kube, t.cfg)
+	r := resolver.NewResolver(t.cfg, t.collection, topology, vars)
+	if err := r.Resolve(); err != nil {
+		return err
+	}
+	i, err := resolver.NewIntegrations(
+		t.cmd.Context(),
+		t.kube,
+		t.cfg,
+		vars,
+		t.appCtx.Name,
+		t.integrationNames,
+	)
+	if err != nil {
+		return err
+	}
+	// The capabilities aren't verified when the cluster can't be inspected.
+	caps, err := resolver.NewCapabilities(t.kube)
+	if err != nil {
+		caps = nil
+	}
+	i.SetProviderVerifier(
+		resolver.NewProviderVerifier(t.cfg, t.collection, vars, caps))
+	solutions, err := i.Solve(topology, t.collection)
+	if err != nil {
+		return err
+	}
+	if t.output == diffOutputJSON {
+		return solutions.PrintJSON(os.Stdout)
+	}
+	solutions.PrintText(os.Stdout, t.scaffold)
+	return nil
+}
+
+// NewTopologyRequirements instantiates the "topology requirements" subcommand,
+// the integration names are the integrations known by the installer.
+func NewTopologyRequirements(
+	appCtx *api.AppContext,
+	cfs chartfs.Interface,
+	integrationNames []string,
+) *TopologyRequirements {
+	t := &TopologyRequirements{
+		cmd: &cobra.Command{
+			Use:          "requirements",
+			Short:        "Solves the integrations required by the topology",
+			Long:         fmt.Sprintf(topologyRequirementsDesc, appCtx.Name),
+			Args:         cobra.NoArgs,
+			SilenceUsage: true,
+		},
+		appCtx:           appCtx,
+		cfs:              cfs,
+		integrationNames: integrationNames,
+	}
+	t.PersistentFlags(t.cmd.PersistentFlags())
+	return t
+}
@@ -0,0 +1,475 @@
+package subcmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/annotations"
+	"github.com/redhat-appstudio/tssc-cli/pkg/chartfs"
+	"github.com/redhat-appstudio/tssc-cli/pkg/config"
+	"github.com/redhat-appstudio/tssc-cli/pkg/deployer"
+	"github.com/redhat-appstudio/tssc-cli/pkg/flags"
+	"github.com/redhat-appstudio/tssc-cli/pkg/k8s"
+	"github.com/redhat-appstudio/tssc-cli/pkg/resolver"
+	"github.com/redhat-appstudio/tssc-cli/pkg/status"
+
+	"github.com/redhat-appstudio/helmet/api"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Uninstall represents the uninstall subcommand, it tears down the deployment
+// removing the Helm releases in the reverse topology order, and optionally the
+// product namespaces, operators, integrations and configuration.
+type Uninstall struct {
+	cmd              *cobra.Command    // cobra command
+	appCtx           *api.AppContext   // application context
+	cfs              chartfs.Interface // installer filesystem
+	flags            *flags.Flags      // global flags
+	logger           *slog.Logger      // application logger
+	integrationNames []string          // known integration names
+
+	kube       k8s.Interface            // kubernetes client
+	manager    *config.ConfigMapManager // cluster configuration manager
+	cfg        *config.Config           // installer configuration
+	collection *resolver.Collection     // chart collection
+
+	products           []string // products to uninstall, all when empty
+	keepData           bool     // keeps the namespaces
+	removeOperators    bool     // removes the OLM subscriptions
+	removeIntegrations bool     // removes the integration secrets
+	removeConfig       bool     // removes the cluster configuration
+}
+
+var _ api.SubCommand = (*Uninstall)(nil)
+
+const uninstallDesc = `
+Uninstalls the %[1]s platform components from the cluster.
+
+The topology is resolved from the cluster configuration, and the Helm releases
+are uninstalled in reverse order, so each chart is removed before the charts it
+depends on. The releases of charts no longer in the topology, e.g. of products
+disabled after deployed, are uninstalled first. Each uninstall waits for the
+release resources to be deleted, including their finalizers. Releases owned by
+another installation are never removed.
+
+Afterwards the product namespaces are deleted, waiting for their finalizers,
+unless "--keep-data" is informed. Only the namespaces created by the deployment,
+labelled with the owning installation, are deleted, the others are listed as
+skipped. The installer namespace is never deleted.
+
+Optionally, the OLM Subscriptions and ClusterServiceVersions of the operators,
+which the Helm uninstall leaves behind, the integration secrets and the cluster
+configuration are removed too.
+
+Use "--product" to uninstall only the charts of the informed products, the
+shared charts are kept. Use the global "--dry-run" flag to inspect what would be
+removed, without changing the cluster.
+
+Examples:
+
+	$ %[1]s uninstall --dry-run
+	$ %[1]s uninstall
+	$ %[1]s uninstall --product "Developer Hub" --keep-data
+	$ %[1]s uninstall --remove-operators --remove-integrations --remove-config
+`
+
+// Cmd exposes the cobra instance.
+func (u *Uninstall) Cmd() *cobra.Command {
+	return u.cmd
+}
+
+// log returns a decorated logger.
+func (u *Uninstall) log() *slog.Logger {
+	return u.flags.LoggerWith(u.logger.With("products", u.products))
+}
+
+// PersistentFlags injects the sub-command flags.
+func (u *Uninstall) PersistentFlags(p *pflag.FlagSet) {
+	p.StringArrayVar(
+		&u.products,
+		"product",
+		[]string{},
+		"Uninstalls only the charts of the product, can be repeated",
+	)
+	p.BoolVar(
+		&u.keepData,
+		"keep-data",
+		false,
+		"Keeps the product namespaces, and their persistent volumes",
+	)
+	p.BoolVar(
+		&u.removeOperators,
+		"remove-operators",
+		false,
+		"Removes the OLM Subscriptions and ClusterServiceVersions",
+	)
+	p.BoolVar(
+		&u.removeIntegrations,
+		"remove-integrations",
+		false,
+		"Removes the integration secrets",
+	)
+	p.BoolVar(
+		&u.removeConfig,
+		"remove-config",
+		false,
+		"Removes the cluster configuration, its history and the deployment "+
+			"status",
+	)
+}
+
+// Complete loads the cluster configuration and the charts collection.
+func (u *Uninstall) Complete(_ []string) error {
+	var err error
+	if u.flags, err = flags.NewFlagsFromCommand(u.cmd); err != nil {
+		return err
+	}
+	u.logger = u.flags.GetLogger(os.Stdout)
+	u.kube = k8s.NewKube(u.flags)
+
+	charts, err := u.cfs.GetAllCharts()
+	if err != nil {
+		return err
+	}
+	if u.collection, err = resolver.NewCollection(u.appCtx, charts); err != nil {
+		return err
+	}
+	u.manager = config.NewConfigMapManager(
+		u.kube, u.appCtx.Name, u.flags.Instance)
+	u.cfg, err = bootstrapConfig(u.cmd.Context(), u.appCtx, u.manager)
+	return err
+}
+
+// Validate asserts the informed products exist, and the flags are consistent.
+func (u *Uninstall) Validate() error {
+	for _, name := range u.products {
+		if _, err := u.cfg.GetProduct(name); err != nil {
+			return err
+		}
+	}
+	if len(u.products) > 0 &&
+		(u.removeOperators || u.removeIntegrations || u.removeConfig) {
+		return fmt.Errorf("--product can't be combined with --remove-operators, " +
+			"--remove-integrations or --remove-config, they affect all products")
+	}
+	if u.keepData && (u.removeIntegrations || u.removeConfig) {
+		return fmt.Errorf("--keep-data can't be combined with " +
+			"--remove-integrations or --remove-config")
+	}
+	return nil
+}
+
+// selected checks whether the dependency belongs to the products to uninstall.
+func (u *Uninstall) selected(d *resolver.Dependency) bool {
+	if len(u.products) == 0 {
+		return true
+	}
+	return slices.Contains(u.products, d.ProductName()) ||
+		slices.Contains(u.products, d.UseProductNamespace())
+}
+
+// plan returns the dependencies to uninstall in order, and the namespaces to
+// delete afterwards. The releases of charts no longer in the topology come first,
+// followed by the topology in reverse order.
+func (u *Uninstall) plan(
+	ctx context.Context,
+) (resolver.Dependencies, []string, error) {
+	topology := resolver.NewTopology()
+	vars := resolver.NewContextFromCluster(ctx, u.kube, u.cfg)
+	r := resolver.NewResolver(u.cfg, u.collection, topology, vars)
+	if err := r.Resolve(); err != nil {
+		return nil, nil, err
+	}
+	releases, err := deployer.NewReleases(
+		u.log(), u.flags, u.kube, "", u.cfg.Namespace())
+	if err != nil {
+		return nil, nil, err
+	}
+	deployed, err := releases.List()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	deps := resolver.Dependencies{}
+	for _, rel := range deployed {
+		if topology.Contains(rel.Name) {
+			continue
+		}
+		d, err := u.collection.Get(rel.Name)
+		if err != nil || !u.selected(d) {
+			continue
+		}
+		deps = append(deps,
+			*resolver.NewDependencyWithNamespace(d.Chart(), rel.Namespace))
+	}
+	all := topology.Dependencies()
+	// Namespaces still in use by the charts kept are not deleted, and the charts
+	// kept can't depend on the charts uninstalled.
+	kept := map[string]bool{u.cfg.Namespace(): true}
+	for i := len(all) - 1; i >= 0; i-- {
+		if u.selected(&all[i]) {
+			deps = append(deps, all[i])
+			continue
+		}
+		kept[all[i].Namespace()] = true
+		for _, name := range all[i].DependsOn() {
+			if d, err := topology.GetDependency(name); err == nil &&
+				u.selected(d) {
+				return nil, nil, fmt.Errorf(
+					"chart %q, which is kept, depends on %q",
+					all[i].Name(), name)
+			}
+		}
+	}
+
+	namespaces := []string{}
+	for _, d := range deps {
+		ns := d.Namespace()
+		if kept[ns] || slices.Contains(namespaces, ns) || ns == "default" ||
+			strings.HasPrefix(ns, "kube-") || strings.HasPrefix(ns, "openshift") {
+			continue
+		}
+		namespaces = append(namespaces, ns)
+	}
+	return deps, namespaces, nil
+}
+
+// ownedNamespaces splits the informed namespaces in the ones created by the
+// deployment of this installation, labelled as such, and the others which are
+// skipped. Namespaces already gone are omitted.
+func (u *Uninstall) ownedNamespaces(
+	ctx context.Context,
+	namespaces []string,
+) ([]string, []string, error) {
+	owned, skipped := []string{}, []string{}
+	for _, name := range namespaces {
+		ns, err := k8s.GetNamespace(ctx, u.kube, name)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch {
+		case ns == nil:
+			continue
+		case ns.GetLabels()[annotations.Instance] == u.cfg.Namespace():
+			owned = append(owned, name)
+		default:
+			skipped = append(skipped, name)
+		}
+	}
+	return owned, skipped, nil
+}
+
+// removeOperatorResources removes the OLM resources left behind by the Helm
+// uninstall, the Subscriptions with their ClusterServiceVersions and the
+// OperatorGroups.
+func (u *Uninstall) removeOperatorResources(
+	ctx context.Context,
+	kept []corev1.ObjectReference,
+) error {
+	for _, ref := range kept {
+		var err error
+		switch ref.Kind {
+		case "Subscription":
+			err = k8s.DeleteSubscription(ctx, u.kube, &ref)
+		case "OperatorGroup":
+			err = k8s.DeleteObject(ctx, u.kube, &ref)
+		default:
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Removed %s %s/%s\n", ref.Kind, ref.Namespace, ref.Name)
+	}
+	return nil
+}
+
+// removeIntegrationSecrets removes the secrets of all known integrations.
+func (u *Uninstall) removeIntegrationSecrets(ctx context.Context) error {
+	for _, name := range u.integrationNames {
+		secret := resolver.IntegrationSecretName(u.cfg, u.appCtx.Name, name)
+		err := k8s.DeleteSecret(ctx, u.kube, secret)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Removed integration %q secret %s\n", name, secret)
+	}
+	return nil
+}
+
+// removeConfiguration removes the cluster configuration, its history, and the
+// deployment state.
+func (u *Uninstall) removeConfiguration(ctx context.Context) error {
+	namespace := u.cfg.Namespace()
+	if err := config.NewHistoryManager(u.kube, u.appCtx.Name).
+		Delete(ctx, namespace); err != nil {
+		return err
+	}
+	if err := status.NewManager(u.kube, u.appCtx.Name).
+		Delete(ctx, namespace); err != nil {
+		return err
+	}
+	if err := u.manager.Delete(ctx); err != nil {
+		return err
+	}
+	fmt.Printf("Removed the configuration from %q\n", namespace)
+	return nil
+}
+
+// forget removes the uninstalled charts from the recorded deployment state, and
+// the checkpoints when all products are uninstalled.
+func (u *Uninstall) forget(ctx context.Context, deps resolver.Dependencies) error {
+	namespace := u.cfg.Namespace()
+	if len(u.products) == 0 {
+		if err := status.NewCheckpointManager(u.kube, u.appCtx.Name).
+			Delete(ctx, namespace); err != nil {
+			return err
+		}
+	}
+	_, err := status.NewManager(u.kube, u.appCtx.Name).Update(
+		ctx,
+		namespace,
+		func(s *status.Status) error {
+			for _, d := range deps {
+				s.Forget(d.Name())
+			}
+			if len(u.products) == 0 {
+				s.Installer = status.Installer{}
+			}
+			return nil
+		},
+	)
+	return err
+}
+
+// Run uninstalls the releases and removes the informed resources.
+func (u *Uninstall) Run() error {
+	ctx := u.cmd.Context()
+	deps, namespaces, err := u.plan(ctx)
+	if err != nil {
+		return err
+	}
+	if len(deps) == 0 {
+		fmt.Printf("Nothing to uninstall.\n")
+		return nil
+	}
+	if u.keepData {
+		namespaces = nil
+	}
+
+	kept := []corev1.ObjectReference{}
+	for i, dep := range deps {
+		fmt.Printf("\n%s\n", strings.Repeat("#", 60))
+		fmt.Printf("# [%d/%d] Uninstalling '%s' from '%s'.\n",
+			i+1, len(deps), dep.Name(), dep.Namespace())
+		fmt.Printf("%s\n", strings.Repeat("#", 60))
+
+		h, err := deployer.NewHelm(
+			dep.LoggerWith(u.log()),
+			os.Stdout,
+			u.flags,
+			u.kube,
+			dep.Namespace(),
+			u.cfg.Namespace(),
+			dep.Chart(),
+		)
+		if err != nil {
+			return err
+		}
+		if u.removeOperators {
+			resources, err := h.KeptResources()
+			if err != nil {
+				return err
+			}
+			kept = append(kept, resources...)
+		}
+		if err = h.Uninstall(); err != nil {
+			return err
+		}
+	}
+
+	namespaces, skipped, err := u.ownedNamespaces(ctx, namespaces)
+	if err != nil {
+		return err
+	}
+	if len(skipped) > 0 {
+		fmt.Printf("\nNamespaces skipped, not created by the installer: %s\n",
+			strings.Join(skipped, ", "))
+	}
+	if u.flags.DryRun {
+		fmt.Printf("\nDry-run, the cluster is unchanged. Namespaces to delete: "+
+			"%s\n", strings.Join(namespaces, ", "))
+		return nil
+	}
+	fmt.Println()
+	if u.removeOperators {
+		if err = u.removeOperatorResources(ctx, kept); err != nil {
+			return err
+		}
+	}
+	for _, ns := range namespaces {
+		fmt.Printf("Deleting namespace %q...\n", ns)
+		if err = k8s.DeleteNamespace(
+			ctx, u.kube, ns, u.flags.Timeout,
+		); err != nil {
+			return err
+		}
+	}
+	if u.removeIntegrations {
+		if err = u.removeIntegrationSecrets(ctx); err != nil {
+			return err
+		}
+	}
+	if u.removeConfig {
+		if err = u.removeConfiguration(ctx); err != nil {
+			return err
+		}
+	} else if err = u.forget(ctx, deps); err != nil {
+		return err
+	}
+
+	fmt.Printf("Uninstall complete!\n")
+	if len(u.products) > 0 {
+		fmt.Printf(`
+The products are still enabled in the configuration, disable them to prevent
+the next deployment from installing them again. For example:
+
+	$ %s config set "products.%s.enabled" false
+`, u.appCtx.Name, u.products[0])
+	}
+	return nil
+}
+
+// NewUninstall instantiates the uninstall subcommand, the integration names are
+// the integrations known by the installer.
+func NewUninstall(
+	appCtx *api.AppContext,
+	cfs chartfs.Interface,
+	integrationNames []string,
+) *Uninstall {
+	u := &Uninstall{
+		cmd: &cobra.Command{
+			Use:          "uninstall",
+			Short:        fmt.Sprintf("Uninstall %s platform components", appCtx.Name),
+			Long:         fmt.Sprintf(uninstallDesc, appCtx.Name),
+			Args:         cobra.NoArgs,
+			SilenceUsage: true,
+		},
+		appCtx:           appCtx,
+		cfs:              cfs,
+		logger:           slog.Default(),
+		integrationNames: integrationNames,
+	}
+	u.PersistentFlags(u.cmd.PersistentFlags())
+	return u
+}
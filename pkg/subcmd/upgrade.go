@@ -0,0 +1,278 @@
+package subcmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/chartfs"
+	"github.com/redhat-appstudio/tssc-cli/pkg/config"
+	"github.com/redhat-appstudio/tssc-cli/pkg/constants"
+	"github.com/redhat-appstudio/tssc-cli/pkg/deployer"
+	"github.com/redhat-appstudio/tssc-cli/pkg/flags"
+	"github.com/redhat-appstudio/tssc-cli/pkg/installer"
+	"github.com/redhat-appstudio/tssc-cli/pkg/k8s"
+	"github.com/redhat-appstudio/tssc-cli/pkg/resolver"
+	"github.com/redhat-appstudio/tssc-cli/pkg/status"
+	"github.com/redhat-appstudio/tssc-cli/pkg/upgrade"
+
+	"github.com/redhat-appstudio/helmet/api"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Upgrade represents the upgrade subcommand, it moves an existing deployment to
+// the embedded installer version, applying migrations before re-deploying.
+type Upgrade struct {
+	cmd    *cobra.Command    // cobra command
+	appCtx *api.AppContext   // application context
+	cfs    chartfs.Interface // installer filesystem
+	flags  *flags.Flags      // global flags
+	logger *slog.Logger      // application logger
+
+	kube             *k8s.Kube                 // kubernetes client
+	manager          *config.ConfigMapManager  // cluster configuration manager
+	cfg              *config.Config            // installer configuration
+	plan             *upgrade.Plan             // upgrade plan
+	topologyBuilder  *resolver.TopologyBuilder // topology builder
+	integrationNames []string                  // known integration names
+
+	valuesTemplatePath string // values template file path
+	fromVersion        string // installed version, when not recorded
+	recoverPending     bool   // recovers pending releases regardless of age
+	allowDowngrade     bool   // replaces deployed charts newer than embedded
+}
+
+var _ api.SubCommand = (*Upgrade)(nil)
+
+const upgradeDesc = `
+Upgrades an existing %s deployment to the version embedded in this executable.
+
+The installed version is the installer version which last deployed all charts,
+recorded in the cluster, it's compared with the version of this executable.
+Downgrades are refused, when the installed version is newer the upgrade stops
+before changing the cluster. Deployments without a recorded version, created by
+older installers, must inform the installed version with "--from-version". The
+deployed chart versions are shown alongside the embedded charts, deployed charts
+newer than the embedded ones are refused as well, unless "--allow-downgrade" is
+informed.
+
+Migration steps registered for the versions in between are applied in order,
+migrating the cluster configuration and removing renamed or obsolete releases.
+Then every Helm release is upgraded in the topology order, as the "deploy"
+subcommand does, using the cluster configuration, the releases already up to
+date are skipped. Releases left pending are recovered as the "deploy" subcommand
+does, use "--recover-pending" to recover them regardless of their age.
+
+Use the global "--dry-run" flag to inspect the upgrade plan and the rendered
+releases without changing the cluster.
+`
+
+// Cmd exposes the cobra instance.
+func (u *Upgrade) Cmd() *cobra.Command {
+	return u.cmd
+}
+
+// log returns a decorated logger.
+func (u *Upgrade) log() *slog.Logger {
+	return u.flags.LoggerWith(u.logger.With(
+		constants.ValuesTemplateFlag, u.valuesTemplatePath,
+	))
+}
+
+// PersistentFlags injects the sub-command flags.
+func (u *Upgrade) PersistentFlags(p *pflag.FlagSet) {
+	p.StringVar(
+		&u.valuesTemplatePath,
+		constants.ValuesTemplateFlag,
+		constants.ValuesFilename,
+		"Path to the values template file",
+	)
+	p.StringVar(
+		&u.fromVersion,
+		"from-version",
+		"",
+		"Installed version, when the cluster has no recorded installer version",
+	)
+	p.BoolVar(
+		&u.recoverPending,
+		"recover-pending",
+		false,
+		"Recovers the releases left pending, even if changed within the timeout",
+	)
+	p.BoolVar(
+		&u.allowDowngrade,
+		"allow-downgrade",
+		false,
+		"Replaces deployed charts newer than the embedded ones",
+	)
+}
+
+// Complete loads the cluster configuration, the deployed releases and the
+// embedded charts to work out the upgrade plan.
+func (u *Upgrade) Complete(_ []string) error {
+	var err error
+	if u.flags, err = flags.NewFlagsFromCommand(u.cmd); err != nil {
+		return err
+	}
+	u.logger = u.flags.GetLogger(os.Stdout)
+	u.kube = k8s.NewKube(u.flags)
+
+	if u.topologyBuilder, err = resolver.NewTopologyBuilder(
+		u.appCtx, u.logger, u.cfs, u.kube, u.integrationNames,
+	); err != nil {
+		return err
+	}
+	if u.manager, err = newConfigMapManager(
+		u.appCtx, u.cfs, u.kube, u.flags.Instance,
+	); err != nil {
+		return err
+	}
+	if u.cfg, err = bootstrapConfig(
+		u.cmd.Context(), u.appCtx, u.manager,
+	); err != nil {
+		return err
+	}
+
+	// The installer which last deployed all charts, recorded in the cluster.
+	s, err := status.NewManager(u.kube, u.appCtx.Name).
+		Get(u.cmd.Context(), u.cfg.Namespace())
+	if err != nil {
+		return err
+	}
+	recorded := s.Installer
+	if u.fromVersion != "" {
+		if recorded.Version != "" && recorded.Version != u.fromVersion {
+			return fmt.Errorf("--from-version %q differs from the recorded "+
+				"installer version %q", u.fromVersion, recorded.Version)
+		}
+		recorded.Version = u.fromVersion
+	}
+
+	charts, err := u.cfs.GetAllCharts()
+	if err != nil {
+		return err
+	}
+	releases, err := deployer.NewReleases(
+		u.log(), u.flags, u.kube, "", u.cfg.Namespace())
+	if err != nil {
+		return err
+	}
+	deployed, err := releases.List()
+	if err != nil {
+		return err
+	}
+	if u.plan, err = upgrade.NewPlan(
+		recorded, u.appCtx.Version, charts, deployed, upgrade.Migrations,
+	); err != nil {
+		if errors.Is(err, upgrade.ErrUnknownVersion) {
+			return fmt.Errorf("%w: the cluster has no recorded installer "+
+				"version, inform it with --from-version", err)
+		}
+		return err
+	}
+	return nil
+}
+
+// Validate asserts the upgrade requirements are in place.
+func (u *Upgrade) Validate() error {
+	if u.plan == nil {
+		panic("upgrade plan is nil")
+	}
+	if _, err := u.cfs.ReadFile(u.valuesTemplatePath); err != nil {
+		return fmt.Errorf("reading values template: %w", err)
+	}
+	if downgrades := u.plan.Downgrades(); len(downgrades) > 0 &&
+		!u.allowDowngrade {
+		charts := make([]string, 0, len(downgrades))
+		for _, s := range downgrades {
+			charts = append(charts, fmt.Sprintf("%s (deployed %s, embedded %s)",
+				s.Release, s.Deployed, s.Embedded))
+		}
+		return fmt.Errorf("%w: %s, use --allow-downgrade to replace them",
+			upgrade.ErrDowngrade, strings.Join(charts, ", "))
+	}
+	return nil
+}
+
+// Run applies the upgrade plan, migrating the deployment and upgrading every
+// Helm release in the topology order. The deployment status is recorded once
+// complete.
+func (u *Upgrade) Run() error {
+	u.plan.Print(os.Stdout)
+	if u.plan.UpToDate() {
+		fmt.Printf("\nThe deployment is already on version %s, re-deploying.\n",
+			u.plan.Target)
+	}
+
+	ctx := u.cmd.Context()
+	u.log().Debug("Applying migrations", "count", len(u.plan.Migrations))
+	previous, err := u.manager.GetConfig(ctx)
+	if err != nil {
+		return err
+	}
+	upgrader := upgrade.NewUpgrader(u.log(), u.flags, u.kube, u.manager)
+	if err = upgrader.Migrate(ctx, u.plan, u.cfg); err != nil {
+		return err
+	}
+	if !u.flags.DryRun {
+		recordConfig(ctx, u.appCtx, u.log(), u.flags, author(u.cmd),
+			fmt.Sprintf("upgrade to %s", u.plan.Target), previous, u.cfg)
+	}
+
+	u.log().Debug("Upgrading the Helm releases")
+	valuesTmpl, err := u.cfs.ReadFile(u.valuesTemplatePath)
+	if err != nil {
+		return err
+	}
+	topology, err := u.topologyBuilder.Build(ctx, u.cfg)
+	if err != nil {
+		return missingIntegrationsHint(u.appCtx, err)
+	}
+	// The helm release timestamps have a second precision on some storage
+	// drivers, therefore the instant is truncated.
+	since := time.Now().Truncate(time.Second)
+	deployment := installer.NewDeployment(
+		u.log(), u.flags, u.kube, u.appCtx.Name, u.cfg, string(valuesTmpl))
+	deployment.SetRecoverPending(u.recoverPending)
+	err = deployment.Run(ctx, topology, deploymentLevels(topology, 1))
+	if !u.flags.DryRun {
+		if recordErr := NewDeployRecorder(u.appCtx, u.cfs).record(
+			ctx, u.flags, since, err == nil, deployment.Failures(),
+		); recordErr != nil {
+			fmt.Fprintf(os.Stderr, "WARNING: unable to record the deployment "+
+				"status: %v\n", recordErr)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Upgrade to version %s complete!\n", u.plan.Target)
+	return nil
+}
+
+// NewUpgrade instantiates the upgrade subcommand, the integration names are the
+// integrations known by the installer, required by the Helm charts.
+func NewUpgrade(
+	appCtx *api.AppContext,
+	cfs chartfs.Interface,
+	integrationNames []string,
+) *Upgrade {
+	u := &Upgrade{
+		cmd: &cobra.Command{
+			Use:          "upgrade",
+			Short:        fmt.Sprintf("Upgrade an existing %s deployment", appCtx.Name),
+			Long:         fmt.Sprintf(upgradeDesc, appCtx.Name),
+			SilenceUsage: true,
+		},
+		appCtx:           appCtx,
+		cfs:              cfs,
+		logger:           slog.Default(),
+		integrationNames: integrationNames,
+	}
+	u.PersistentFlags(u.cmd.PersistentFlags())
+	return u
+}
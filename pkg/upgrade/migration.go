@@ -0,0 +1,78 @@
+package upgrade
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/config"
+	"github.com/redhat-appstudio/tssc-cli/pkg/deployer"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// Migration represents the changes required to bring an existing deployment to
+// the informed installer version, applied before the Helm releases are upgraded.
+type Migration struct {
+	// Version installer version introducing the changes.
+	Version string
+	// Description human readable summary of the migration.
+	Description string
+	// RenamedCharts maps the previous chart name to the current one, the previous
+	// release is uninstalled and the current chart is installed by the deploy.
+	RenamedCharts map[string]string
+	// RemovedReleases releases no longer part of the installer, uninstalled.
+	RemovedReleases []string
+	// Config changes the cluster configuration schema, optional.
+	Config func(*config.Config) error
+}
+
+// Migrations ordered registry of migration steps, every release changing the
+// configuration schema, renaming charts or dropping releases must append an
+// entry for its version.
+var Migrations = []Migration{{
+	Version:     "1.10.0",
+	Description: `adds the "failurePolicy" setting, failed releases are kept`,
+	Config:      addFailurePolicy,
+}}
+
+// addFailurePolicy adds the "failurePolicy" setting to configurations created
+// before it existed, keeping failed releases as those installers did.
+func addFailurePolicy(cfg *config.Config) error {
+	if _, ok := cfg.Installer.Settings["failurePolicy"]; ok {
+		return nil
+	}
+	return cfg.SetPath("settings.failurePolicy", deployer.FailurePolicyLeave)
+}
+
+// pendingMigrations returns the migrations applicable when moving from the
+// installed version to the target, inclusive, sorted by version.
+func pendingMigrations(
+	migrations []Migration,
+	installed *semver.Version,
+	target *semver.Version,
+) ([]Migration, error) {
+	type versioned struct {
+		v *semver.Version
+		m Migration
+	}
+	pending := []versioned{}
+	for _, m := range migrations {
+		v, err := semver.NewVersion(m.Version)
+		if err != nil {
+			return nil, fmt.Errorf("%w: migration %q: %w",
+				ErrInvalidVersion, m.Version, err)
+		}
+		if v.GreaterThan(installed) && !v.GreaterThan(target) {
+			pending = append(pending, versioned{v: v, m: m})
+		}
+	}
+	slices.SortStableFunc(pending, func(a, b versioned) int {
+		return a.v.Compare(b.v)
+	})
+
+	result := make([]Migration, 0, len(pending))
+	for _, p := range pending {
+		result = append(result, p.m)
+	}
+	return result, nil
+}
@@ -0,0 +1,52 @@
+package upgrade
+
+import (
+	"testing"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/config"
+	"github.com/redhat-appstudio/tssc-cli/pkg/deployer"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+func TestMigrationsFailurePolicy(t *testing.T) {
+	pending, err := pendingMigrations(Migrations,
+		semver.MustParse("1.9.0"), semver.MustParse("1.10.0"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pending) != 1 || pending[0].Config == nil {
+		t.Fatalf("expected the 1.10.0 configuration migration, got %+v", pending)
+	}
+
+	tests := []struct {
+		name     string
+		settings string
+		policy   string
+	}{{
+		name:     "setting missing",
+		settings: "crc: false",
+		policy:   deployer.FailurePolicyLeave,
+	}, {
+		name:     "setting informed",
+		settings: "failurePolicy: rollback",
+		policy:   deployer.FailurePolicyRollback,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := "---\ntssc:\n  settings:\n    " + tt.settings +
+				"\n  products: []\n"
+			cfg, err := config.NewConfigFromBytes([]byte(payload), "tssc", "tssc")
+			if err != nil {
+				t.Fatalf("loading configuration: %v", err)
+			}
+			if err = pending[0].Config(cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if policy := cfg.Installer.Settings["failurePolicy"]; policy != tt.policy {
+				t.Errorf("expected failure policy %q, got %v", tt.policy, policy)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package upgrade
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/status"
+
+	"github.com/Masterminds/semver/v3"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// Action represents what the upgrade does with a given Helm release.
+type Action string
+
+const (
+	// ActionUpgrade the release is upgraded to the embedded chart version.
+	ActionUpgrade Action = "upgrade"
+	// ActionReconcile the release is already on the embedded chart version, it's
+	// re-applied with the current configuration, when it differs.
+	ActionReconcile Action = "reconcile"
+	// ActionDowngrade the deployed chart is newer than the embedded one, it's
+	// only replaced when downgrades are allowed.
+	ActionDowngrade Action = "downgrade"
+	// ActionUninstall the release is removed by a migration step.
+	ActionUninstall Action = "uninstall"
+)
+
+// Step represents the upgrade of a single Helm release.
+type Step struct {
+	Release   string // helm release name
+	Namespace string // release namespace
+	Deployed  string // deployed chart version
+	Embedded  string // embedded chart version, empty when not embedded
+	Action    Action // upgrade action
+	Reason    string // reason for the action, migration description
+}
+
+// Plan represents the sequence of steps to upgrade an existing deployment.
+type Plan struct {
+	Installed  *semver.Version  // installed installer version
+	Target     *semver.Version  // target installer version, this executable
+	Recorded   status.Installer // installer recorded in the cluster
+	Migrations []Migration      // pending migrations, sorted by version
+	Steps      []Step           // per release steps
+}
+
+var (
+	// ErrNotDeployed there are no releases of the installer charts in the
+	// cluster, the deployment must be created first.
+	ErrNotDeployed = errors.New("no existing deployment found")
+	// ErrUnknownVersion the installer version of the existing deployment is not
+	// recorded in the cluster.
+	ErrUnknownVersion = errors.New("installed version is unknown")
+	// ErrDowngrade the cluster runs an installer newer than the target.
+	ErrDowngrade = errors.New("downgrade is not supported")
+	// ErrInvalidVersion the installer, chart or migration version is not
+	// semantic.
+	ErrInvalidVersion = errors.New("invalid version")
+)
+
+// UpToDate checks whether the deployment already runs the target version, and
+// there are no pending migrations.
+func (p *Plan) UpToDate() bool {
+	return p.Installed.Equal(p.Target) && len(p.Migrations) == 0
+}
+
+// stepsWith returns the steps with the informed action.
+func (p *Plan) stepsWith(action Action) []Step {
+	steps := []Step{}
+	for _, s := range p.Steps {
+		if s.Action == action {
+			steps = append(steps, s)
+		}
+	}
+	return steps
+}
+
+// Uninstalls returns the steps removing releases from the cluster.
+func (p *Plan) Uninstalls() []Step {
+	return p.stepsWith(ActionUninstall)
+}
+
+// Downgrades returns the steps replacing a deployed chart by an older one.
+func (p *Plan) Downgrades() []Step {
+	return p.stepsWith(ActionDowngrade)
+}
+
+// Print prints the upgrade plan to the writer formatted as a table.
+func (p *Plan) Print(w io.Writer) {
+	fmt.Fprintf(w, "Installed version: %s", p.Installed)
+	if p.Recorded.CommitID != "" {
+		fmt.Fprintf(w, " (commit %q, deployed at %s)", p.Recorded.CommitID,
+			p.Recorded.Updated.Format(time.RFC3339))
+	}
+	fmt.Fprintf(w, "\nTarget version: %s\n\n", p.Target)
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	row := func(a ...any) {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\n", a...)
+	}
+	row("Release", "Namespace", "Deployed", "Embedded", "Action", "Reason")
+	for _, s := range p.Steps {
+		row(s.Release, s.Namespace, s.Deployed, s.Embedded, s.Action, s.Reason)
+	}
+	table.Flush()
+
+	if len(p.Migrations) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nMigrations:\n")
+	for _, m := range p.Migrations {
+		fmt.Fprintf(w, "  - %s: %s\n", m.Version, m.Description)
+	}
+}
+
+// chartVersion parses the chart metadata version.
+func chartVersion(md *chart.Metadata) (*semver.Version, error) {
+	if md == nil {
+		return nil, fmt.Errorf("%w: missing chart metadata", ErrInvalidVersion)
+	}
+	v, err := semver.NewVersion(md.Version)
+	if err != nil {
+		return nil, fmt.Errorf("%w: chart %q version %q: %w",
+			ErrInvalidVersion, md.Name, md.Version, err)
+	}
+	return v, nil
+}
+
+// parseVersion parses the installer version, the name describes its source.
+func parseVersion(name, version string) (*semver.Version, error) {
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s %q: %w",
+			ErrInvalidVersion, name, version, err)
+	}
+	return v, nil
+}
+
+// NewPlan compares the installer recorded in the cluster by the last complete
+// deployment with the target installer version to refuse downgrades and to
+// select the pending migrations. The deployed Helm releases
+// are compared with the embedded charts to work out the steps per release.
+// Release names are expected to match the chart names.
+func NewPlan(
+	recorded status.Installer,
+	target string,
+	charts []chart.Chart,
+	releases []*release.Release,
+	migrations []Migration,
+) (*Plan, error) {
+	p := &Plan{Recorded: recorded, Steps: []Step{}}
+	if recorded.Version == "" {
+		return nil, ErrUnknownVersion
+	}
+	var err error
+	if p.Installed, err = parseVersion(
+		"installed version", recorded.Version,
+	); err != nil {
+		return nil, err
+	}
+	if p.Target, err = parseVersion("target version", target); err != nil {
+		return nil, err
+	}
+	if p.Installed.GreaterThan(p.Target) {
+		return nil, fmt.Errorf("%w: installed version %s is newer than %s",
+			ErrDowngrade, p.Installed, p.Target)
+	}
+
+	embedded := map[string]*semver.Version{}
+	for _, hc := range charts {
+		v, err := chartVersion(hc.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		embedded[hc.Name()] = v
+	}
+
+	// Inspecting the releases of embedded charts, the installer version drives
+	// the upgrade, while deployed charts newer than the embedded ones are
+	// downgrades.
+	deployed := map[string]*release.Release{}
+	for _, rel := range releases {
+		if rel.Chart == nil {
+			continue
+		}
+		deployed[rel.Name] = rel
+		embeddedVersion, ok := embedded[rel.Name]
+		if !ok {
+			continue
+		}
+		v, err := chartVersion(rel.Chart.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		step := Step{
+			Release:   rel.Name,
+			Namespace: rel.Namespace,
+			Deployed:  v.String(),
+			Embedded:  embeddedVersion.String(),
+			Action:    ActionReconcile,
+		}
+		if v.LessThan(embeddedVersion) {
+			step.Action = ActionUpgrade
+		}
+		if v.GreaterThan(embeddedVersion) {
+			step.Action = ActionDowngrade
+			step.Reason = "deployed chart is newer"
+		}
+		p.Steps = append(p.Steps, step)
+	}
+	if len(p.Steps) == 0 {
+		return nil, ErrNotDeployed
+	}
+
+	if p.Migrations, err = pendingMigrations(
+		migrations, p.Installed, p.Target,
+	); err != nil {
+		return nil, err
+	}
+
+	// Releases renamed or removed by the pending migrations are uninstalled, when
+	// still present in the cluster.
+	uninstall := func(name, reason string) {
+		rel, ok := deployed[name]
+		if !ok {
+			return
+		}
+		p.Steps = append(p.Steps, Step{
+			Release:   rel.Name,
+			Namespace: rel.Namespace,
+			Deployed:  rel.Chart.Metadata.Version,
+			Action:    ActionUninstall,
+			Reason:    reason,
+		})
+	}
+	for _, m := range p.Migrations {
+		for previous, current := range m.RenamedCharts {
+			uninstall(previous, fmt.Sprintf("renamed to %q", current))
+		}
+		for _, name := range m.RemovedReleases {
+			uninstall(name, fmt.Sprintf("removed in %s", m.Version))
+		}
+	}
+
+	slices.SortStableFunc(p.Steps, func(a, b Step) int {
+		return strings.Compare(a.Release, b.Release)
+	})
+	return p, nil
+}
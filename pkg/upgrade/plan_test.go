@@ -0,0 +1,146 @@
+package upgrade
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/status"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func newChart(name, version string) chart.Chart {
+	return chart.Chart{Metadata: &chart.Metadata{Name: name, Version: version}}
+}
+
+func newRelease(name, version string) *release.Release {
+	hc := newChart(name, version)
+	return &release.Release{Name: name, Namespace: "ns", Chart: &hc}
+}
+
+func TestNewPlan(t *testing.T) {
+	charts := []chart.Chart{newChart("a", "1.1.0"), newChart("b", "1.0.0")}
+	migrations := []Migration{
+		{Version: "1.0.0", RemovedReleases: []string{"old"}},
+		{Version: "1.1.0", RenamedCharts: map[string]string{"legacy": "b"}},
+		{Version: "1.2.0", RemovedReleases: []string{"b"}},
+	}
+
+	tests := []struct {
+		name       string
+		installed  string
+		target     string
+		releases   []*release.Release
+		err        error
+		migrations []string
+		steps      map[string]Action
+		upToDate   bool
+	}{{
+		name:      "unknown installed version",
+		installed: "",
+		target:    "1.1.0",
+		releases:  []*release.Release{newRelease("a", "1.0.0")},
+		err:       ErrUnknownVersion,
+	}, {
+		name:      "invalid target version",
+		installed: "1.0.0",
+		target:    "snapshot",
+		releases:  []*release.Release{newRelease("a", "1.0.0")},
+		err:       ErrInvalidVersion,
+	}, {
+		name:      "downgrade",
+		installed: "1.2.0",
+		target:    "1.1.0",
+		releases:  []*release.Release{newRelease("a", "1.0.0")},
+		err:       ErrDowngrade,
+	}, {
+		name:      "not deployed",
+		installed: "1.0.0",
+		target:    "1.1.0",
+		releases:  []*release.Release{newRelease("other", "1.0.0")},
+		err:       ErrNotDeployed,
+	}, {
+		name:      "migrations between versions",
+		installed: "1.0.0",
+		target:    "1.1.0",
+		releases: []*release.Release{
+			newRelease("a", "1.0.0"),
+			newRelease("b", "1.0.0"),
+			newRelease("legacy", "0.9.0"),
+			newRelease("old", "0.9.0"),
+		},
+		migrations: []string{"1.1.0"},
+		steps: map[string]Action{
+			"a":      ActionUpgrade,
+			"b":      ActionReconcile,
+			"legacy": ActionUninstall,
+		},
+	}, {
+		name:      "newer deployed chart is a downgrade",
+		installed: "1.1.0",
+		target:    "1.1.0",
+		releases:  []*release.Release{newRelease("a", "2.0.0")},
+		steps:     map[string]Action{"a": ActionDowngrade},
+		upToDate:  true,
+	}, {
+		name:      "same version",
+		installed: "v1.1.0",
+		target:    "1.1.0",
+		releases:  []*release.Release{newRelease("a", "1.1.0")},
+		steps:     map[string]Action{"a": ActionReconcile},
+		upToDate:  true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewPlan(status.Installer{Version: tt.installed},
+				tt.target, charts, tt.releases, migrations)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected error %v, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			versions := []string{}
+			for _, m := range p.Migrations {
+				versions = append(versions, m.Version)
+			}
+			if len(versions) != len(tt.migrations) {
+				t.Fatalf("expected migrations %v, got %v",
+					tt.migrations, versions)
+			}
+			for i := range versions {
+				if versions[i] != tt.migrations[i] {
+					t.Fatalf("expected migrations %v, got %v",
+						tt.migrations, versions)
+				}
+			}
+			if len(p.Steps) != len(tt.steps) {
+				t.Fatalf("expected %d steps, got %+v", len(tt.steps), p.Steps)
+			}
+			for _, s := range p.Steps {
+				if tt.steps[s.Release] != s.Action {
+					t.Errorf("release %q: expected %q, got %q",
+						s.Release, tt.steps[s.Release], s.Action)
+				}
+			}
+			downgrades := 0
+			for _, a := range tt.steps {
+				if a == ActionDowngrade {
+					downgrades++
+				}
+			}
+			if len(p.Downgrades()) != downgrades {
+				t.Errorf("expected %d downgrades, got %+v",
+					downgrades, p.Downgrades())
+			}
+			if p.UpToDate() != tt.upToDate {
+				t.Errorf("expected up to date %v", tt.upToDate)
+			}
+		})
+	}
+}
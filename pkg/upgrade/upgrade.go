@@ -0,0 +1,93 @@
+package upgrade
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/redhat-appstudio/tssc-cli/pkg/config"
+	"github.com/redhat-appstudio/tssc-cli/pkg/deployer"
+	"github.com/redhat-appstudio/tssc-cli/pkg/flags"
+	"github.com/redhat-appstudio/tssc-cli/pkg/k8s"
+)
+
+// Upgrader applies the upgrade plan migrations on the cluster, it's responsible
+// for removing obsolete releases and for migrating the cluster configuration.
+type Upgrader struct {
+	logger  *slog.Logger             // application logger
+	flags   *flags.Flags             // global flags
+	kube    k8s.Interface            // kubernetes client
+	manager *config.ConfigMapManager // cluster configuration manager
+}
+
+// uninstall removes the release informed on the plan step, owned by the
+// informed installation.
+func (u *Upgrader) uninstall(step Step, instance string) error {
+	releases, err := deployer.NewReleases(
+		u.logger, u.flags, u.kube, step.Namespace, instance)
+	if err != nil {
+		return err
+	}
+	return releases.Uninstall(step.Release)
+}
+
+// Migrate applies the plan migrations, the cluster configuration is migrated in
+// order and stored once all steps succeed, then obsolete releases are removed.
+func (u *Upgrader) Migrate(
+	ctx context.Context,
+	plan *Plan,
+	cfg *config.Config,
+) error {
+	changed := false
+	for _, m := range plan.Migrations {
+		if m.Config == nil {
+			continue
+		}
+		u.logger.Info("Migrating the cluster configuration",
+			"version", m.Version, "description", m.Description)
+		if err := m.Config(cfg); err != nil {
+			return fmt.Errorf("migrating configuration to %s: %w",
+				m.Version, err)
+		}
+		changed = true
+	}
+	if changed {
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+		if err := u.manager.Validate(cfg); err != nil {
+			return err
+		}
+		if u.flags.DryRun {
+			u.logger.Info("Dry-run mode enabled, skipping configuration update")
+			fmt.Printf("#\n# Migrated configuration (dry-run)\n#\n\n%s\n",
+				cfg.String())
+		} else if err := u.manager.Update(ctx, cfg); err != nil {
+			return err
+		}
+	}
+
+	for _, step := range plan.Uninstalls() {
+		u.logger.Info("Removing obsolete release",
+			"release", step.Release, "reason", step.Reason)
+		if err := u.uninstall(step, cfg.Namespace()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewUpgrader instantiates the Upgrader.
+func NewUpgrader(
+	logger *slog.Logger,
+	f *flags.Flags,
+	kube k8s.Interface,
+	manager *config.ConfigMapManager,
+) *Upgrader {
+	return &Upgrader{
+		logger:  logger.With("type", "upgrade"),
+		flags:   f,
+		kube:    kube,
+		manager: manager,
+	}
+}